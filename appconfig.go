@@ -0,0 +1,365 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Poll interval of AppConfig session, used if AppConfigSource.PollInterval is not set
+const defaultAppConfigPollInterval = time.Minute
+
+// Content types of YAML profiles. AppConfig returns them for YAML freeform profiles
+var appConfigYAMLTypes = map[string]bool{
+	"application/x-yaml": true,
+	"application/yaml":   true,
+	"text/yaml":          true,
+	"text/x-yaml":        true,
+}
+
+// Source that fetches hosted configuration profile of AWS AppConfig (with AppConfigData api).
+// JSON profiles are parsed with the same parsers as config file, YAML ones are converted to JSON first (plain text
+// profiles are rejected). Session is kept between loads, and Watch polls it for new configuration versions
+type AppConfigSource struct {
+	Application  string // Application id or name
+	Environment  string // Environment id or name
+	Profile      string // Configuration profile id or name
+	Region       string // Default is AWS_REGION or AWS_DEFAULT_REGION env variable
+	Endpoint     string // Custom api url. Default is https://appconfigdata.<region>.amazonaws.com
+	PollInterval time.Duration
+	AccessKey    string // Default is AWS_ACCESS_KEY_ID env variable
+	SecretKey    string // Default is AWS_SECRET_ACCESS_KEY env variable
+	SessionToken string // Default is AWS_SESSION_TOKEN env variable
+	Client       *http.Client
+
+	mu            sync.Mutex
+	token         string            // Token for next GetLatestConfiguration call
+	values        map[string]string // Last received configuration, because api returns empty body if it is not changed
+	nextPoll      time.Duration     // Poll interval requested by api
+	contentLoaded bool
+}
+
+func (s *AppConfigSource) Name() string {
+	return "appconfig"
+}
+
+// Return latest configuration. Session is started on first call
+func (s *AppConfigSource) Load(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.poll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return copyValues(s.values), nil
+}
+
+// Poll session for new configuration versions. Block until ctx is done or polling is failed
+func (s *AppConfigSource) Watch(ctx context.Context, onChange func()) error {
+	for {
+		interval := s.PollInterval
+		if interval == 0 {
+			interval = defaultAppConfigPollInterval
+		}
+		s.mu.Lock()
+		if s.nextPoll > interval {
+			interval = s.nextPoll
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+
+		s.mu.Lock()
+		changed, err := s.poll(ctx)
+		s.mu.Unlock()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if changed {
+			onChange()
+		}
+	}
+}
+
+// Fetch latest configuration and save it. Return true if new configuration was received.
+// Content type should be JSON or YAML
+func (s *AppConfigSource) poll(ctx context.Context) (bool, error) {
+	if s.token == "" {
+		err := s.startSession(ctx)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	resp, err := s.call(ctx, http.MethodGet, "/configuration?configuration_token="+url.QueryEscape(s.token), nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusBadRequest {
+			s.token = "" // Token is expired, so new session will be started with next poll
+		}
+		return false, errors.New(fmt.Sprintf("AppConfig responded with %s: %s", resp.Status, body))
+	}
+
+	s.token = resp.Header.Get("Next-Poll-Configuration-Token")
+	if seconds, err := strconv.Atoi(resp.Header.Get("Next-Poll-Interval-In-Seconds")); err == nil {
+		s.nextPoll = time.Duration(seconds) * time.Second
+	}
+
+	if len(body) == 0 && s.contentLoaded { // Configuration is not changed since last poll
+		return false, nil
+	}
+
+	parsed := &Parser{parsedCfg: make(map[string]string)}
+	if len(body) > 0 {
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		ext, ok := contentTypes[mediaType]
+		if appConfigYAMLTypes[mediaType] {
+			body, err = yamlToJSON(body)
+			if err != nil {
+				return false, err
+			}
+			ext, ok = ".json", true
+		}
+		if !ok {
+			return false, errors.New(fmt.Sprintf("Unsupported config content type %s", resp.Header.Get("Content-Type")))
+		}
+
+		err = parsed.decodeCfg(body, ext)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	s.values = parsed.parsedCfg
+	s.contentLoaded = true
+	return true, nil
+}
+
+// Convert YAML document into JSON, so it can be parsed as config file
+func yamlToJSON(content []byte) ([]byte, error) {
+	tmp := make(map[string]interface{})
+	err := yaml.Unmarshal(content, &tmp)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse YAML profile: %w", err)
+	}
+
+	return json.Marshal(tmp)
+}
+
+// Start configuration session and save its initial token
+func (s *AppConfigSource) startSession(ctx context.Context) error {
+	pollInterval := s.PollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultAppConfigPollInterval
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"ApplicationIdentifier":                s.Application,
+		"EnvironmentIdentifier":                s.Environment,
+		"ConfigurationProfileIdentifier":       s.Profile,
+		"RequiredMinimumPollIntervalInSeconds": int(pollInterval.Seconds()),
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.call(ctx, http.MethodPost, "/configurationsessions", payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return errors.New(fmt.Sprintf("AppConfig session start failed with %s: %s", resp.Status, body))
+	}
+
+	var session struct {
+		InitialConfigurationToken string
+	}
+	err = json.NewDecoder(resp.Body).Decode(&session)
+	if err != nil {
+		return err
+	}
+
+	s.token = session.InitialConfigurationToken
+	s.contentLoaded = false
+	return nil
+}
+
+// Send signed request to AppConfigData api
+func (s *AppConfigSource) call(ctx context.Context, method, path string, payload []byte) (*http.Response, error) {
+	region := firstNonEmpty(s.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"))
+	if region == "" {
+		return nil, errors.New("AWS region is not set")
+	}
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://appconfigdata.%s.amazonaws.com", region)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(endpoint, "/")+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	creds := awsCredentials{
+		accessKey:    firstNonEmpty(s.AccessKey, os.Getenv("AWS_ACCESS_KEY_ID")),
+		secretKey:    firstNonEmpty(s.SecretKey, os.Getenv("AWS_SECRET_ACCESS_KEY")),
+		sessionToken: firstNonEmpty(s.SessionToken, os.Getenv("AWS_SESSION_TOKEN")),
+	}
+	awsSign(req, payload, creds, region, "appconfig", time.Now())
+
+	return client.Do(req)
+}
+
+// Keys for AWS request signing
+type awsCredentials struct {
+	accessKey    string
+	secretKey    string
+	sessionToken string
+}
+
+// Sign request with AWS Signature Version 4. Host and all x-amz-* headers are signed
+func awsSign(req *http.Request, payload []byte, creds awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		key = strings.ToLower(key)
+		if strings.HasPrefix(key, "x-amz-") {
+			headers[key] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	headerNames := make([]string, 0, len(headers))
+	for key := range headers {
+		headerNames = append(headerNames, key)
+	}
+	sort.Strings(headerNames)
+
+	canonicalHeaders := bytes.NewBufferString("")
+	for _, key := range headerNames {
+		canonicalHeaders.WriteString(fmt.Sprintf("%s:%s\n", key, headers[key]))
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	query := req.URL.Query()
+	queryKeys := make([]string, 0, len(query))
+	for key := range query {
+		queryKeys = append(queryKeys, key)
+	}
+	sort.Strings(queryKeys)
+	canonicalQuery := []string{}
+	for _, key := range queryKeys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			canonicalQuery = append(canonicalQuery, awsURIEncode(key)+"="+awsURIEncode(value))
+		}
+	}
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	payloadHash := sha256.Sum256(payload)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.Join(canonicalQuery, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", creds.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// Encode string as AWS requires: everything except unreserved chars is percent-encoded
+func awsURIEncode(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			b.WriteString(fmt.Sprintf("%%%02X", c))
+		}
+	}
+
+	return b.String()
+}
+
+// Return first not empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAppConfigSource_Load(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/configurationsessions":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"InitialConfigurationToken":"token0"}`))
+		case "/configuration":
+			token := r.URL.Query().Get("configuration_token")
+			if token != "token"+string(rune('0'+polls)) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			polls++
+			w.Header().Set("Next-Poll-Configuration-Token", "token"+string(rune('0'+polls)))
+			w.Header().Set("Next-Poll-Interval-In-Seconds", "0")
+			w.Header().Set("Content-Type", "application/json")
+			switch polls {
+			case 1:
+				w.Write([]byte(`{"port":8080,"db":{"host":"localhost"}}`))
+			case 3:
+				w.Write([]byte(`{"port":9090}`))
+			}
+		}
+	}))
+	defer server.Close()
+
+	source := &AppConfigSource{
+		Application: "app",
+		Environment: "prod",
+		Profile:     "main",
+		Region:      "eu-west-1",
+		Endpoint:    server.URL,
+		AccessKey:   "key",
+		SecretKey:   "secret",
+	}
+
+	got, err := source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("AppConfigSource.Load() error = %v", err)
+	}
+	if want := map[string]string{"port": "8080", "db.host": "localhost"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AppConfigSource.Load() = %v, want %v", got, want)
+	}
+
+	// Not changed configuration is returned as empty body
+	got, err = source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("AppConfigSource.Load() error = %v", err)
+	}
+	if want := map[string]string{"port": "8080", "db.host": "localhost"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AppConfigSource.Load() = %v, want %v", got, want)
+	}
+
+	source.PollInterval = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- source.Watch(ctx, cancel)
+	}()
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Errorf("AppConfigSource.Watch() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("AppConfigSource.Watch() did not report change")
+	}
+
+	got, _ = source.Load(context.Background())
+	if want := map[string]string{"port": "9090"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AppConfigSource.Load() = %v, want %v", got, want)
+	}
+
+	forbidden := &AppConfigSource{Region: "eu-west-1", Endpoint: server.URL, AccessKey: "zzz"}
+	if _, err = forbidden.Load(context.Background()); err == nil {
+		t.Errorf("AppConfigSource.Load() should fail with wrong credentials")
+	}
+}
+
+func TestAppConfigSource_yaml(t *testing.T) {
+	type testStruct struct {
+		Port int `config:"name:port;mode:cfg"`
+		DB   struct {
+			Host string `config:"name:host;mode:cfg"`
+		} `config:"name:db;mode:cfg"`
+	}
+
+	var mu sync.Mutex
+	polls := 0
+	published := false // Second profile version is returned once test publishes it
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/configurationsessions":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"InitialConfigurationToken":"token"}`))
+		case "/configuration":
+			mu.Lock()
+			current := 0
+			if polls == 0 || published {
+				polls++
+				current = polls
+			}
+			mu.Unlock()
+			w.Header().Set("Next-Poll-Configuration-Token", "token")
+			w.Header().Set("Content-Type", "application/x-yaml")
+			switch current {
+			case 1:
+				w.Write([]byte("port: 8080\ndb:\n  host: localhost\n"))
+			case 2:
+				w.Write([]byte("port: 9090\ndb:\n  host: db\n"))
+			}
+		}
+	}))
+	defer server.Close()
+
+	os.Args = []string{"/app/test"}
+	changes := make(chan struct{}, 10)
+
+	var cfg testStruct
+	source := &AppConfigSource{Region: "eu-west-1", Endpoint: server.URL, PollInterval: time.Millisecond}
+	p, err := NewParser(&cfg, WithSource(source), WithWatch(), WithOnChange(func() { changes <- struct{}{} }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if cfg.Port != 8080 || cfg.DB.Host != "localhost" {
+		t.Errorf("Parsed config = %v, want port 8080 and db host localhost", cfg)
+	}
+
+	mu.Lock()
+	published = true
+	mu.Unlock()
+
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatalf("New profile version is not applied")
+	}
+	p.mu.Lock()
+	if cfg.Port != 9090 || cfg.DB.Host != "db" {
+		t.Errorf("Watched config = %v, want port 9090 and db host db", cfg)
+	}
+	p.mu.Unlock()
+
+	if _, err = yamlToJSON([]byte("port: [")); err == nil {
+		t.Errorf("yamlToJSON() should fail for invalid YAML")
+	}
+}
+
+func Test_awsSign(t *testing.T) {
+	// "get-vanilla-query-order-key-case" case of AWS Signature Version 4 test suite
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/?Param2=value2&Param1=value1", nil)
+	creds := awsCredentials{accessKey: "AKIDEXAMPLE", secretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	awsSign(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("awsSign() = %v, want %v", got, want)
+	}
+}
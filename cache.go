@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Source wrapper that keeps loaded values in memory for ttl,
+// so repeated parsing doesn't request remote backend each time
+type CachedSource struct {
+	src      Source
+	ttl      time.Duration
+	mu       sync.Mutex
+	values   map[string]string
+	loadedAt time.Time
+}
+
+// Wrap source with in-memory cache. Values are loaded again after ttl is expired
+func NewCachedSource(src Source, ttl time.Duration) *CachedSource {
+	return &CachedSource{src: src, ttl: ttl}
+}
+
+// Name of wrapped source, so source settings (timeout, retries) are applied to it
+func (s *CachedSource) Name() string {
+	return s.src.Name()
+}
+
+// Return cached values if they are not expired, otherwise load them from wrapped source
+func (s *CachedSource) Load(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values != nil && time.Since(s.loadedAt) < s.ttl {
+		return copyValues(s.values), nil
+	}
+
+	return s.load(ctx)
+}
+
+// Return name of source that provided config name, if wrapped source combines other sources (see OriginSource)
+func (s *CachedSource) Origin(name string) (string, bool) {
+	combined, ok := s.src.(OriginSource)
+	if !ok {
+		return "", false
+	}
+
+	return combined.Origin(name)
+}
+
+// Load values from wrapped source ignoring cache, and save them
+func (s *CachedSource) Refresh(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load(ctx)
+}
+
+// Drop cached values, so next Load requests wrapped source
+func (s *CachedSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = nil
+}
+
+// Load values from wrapped source into cache. Should be called under lock
+func (s *CachedSource) load(ctx context.Context) (map[string]string, error) {
+	values, err := s.src.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.values = copyValues(values)
+	s.loadedAt = time.Now()
+	return values, nil
+}
+
+// Copy values map, so cached values can't be modified by caller
+func copyValues(values map[string]string) map[string]string {
+	result := make(map[string]string, len(values))
+	for k, v := range values {
+		result[k] = v
+	}
+
+	return result
+}
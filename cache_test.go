@@ -0,0 +1,61 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Source that counts its loads
+type countingSource struct {
+	loads int
+}
+
+func (s *countingSource) Name() string {
+	return "counting"
+}
+
+func (s *countingSource) Load(ctx context.Context) (map[string]string, error) {
+	s.loads++
+	return map[string]string{"loads": string(rune('0' + s.loads))}, nil
+}
+
+func TestCachedSource_Load(t *testing.T) {
+	src := &countingSource{}
+	cached := NewCachedSource(src, 50*time.Millisecond)
+
+	if cached.Name() != "counting" {
+		t.Errorf("CachedSource.Name() = %v, want %v", cached.Name(), "counting")
+	}
+
+	steps := []struct {
+		name      string
+		action    func() (map[string]string, error)
+		wantLoads int
+	}{
+		{name: "first", action: func() (map[string]string, error) { return cached.Load(context.Background()) }, wantLoads: 1},
+		{name: "cached", action: func() (map[string]string, error) { return cached.Load(context.Background()) }, wantLoads: 1},
+		{name: "refresh", action: func() (map[string]string, error) { return cached.Refresh(context.Background()) }, wantLoads: 2},
+		{name: "cached after refresh", action: func() (map[string]string, error) { return cached.Load(context.Background()) }, wantLoads: 2},
+		{name: "invalidate", action: func() (map[string]string, error) {
+			cached.Invalidate()
+			return cached.Load(context.Background())
+		}, wantLoads: 3},
+		{name: "expired", action: func() (map[string]string, error) {
+			time.Sleep(60 * time.Millisecond)
+			return cached.Load(context.Background())
+		}, wantLoads: 4},
+	}
+	for _, step := range steps {
+		got, err := step.action()
+		if err != nil {
+			t.Fatalf("%s: error = %v", step.name, err)
+		}
+		if src.loads != step.wantLoads {
+			t.Errorf("%s: loads = %v, want %v", step.name, src.loads, step.wantLoads)
+		}
+		if got["loads"] != string(rune('0'+step.wantLoads)) {
+			t.Errorf("%s: values = %v", step.name, got)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Source that resolves each key from the first source of chain that has it. Ex.: Vault -> SSM -> file.
+// Failed sources are skipped, so next ones are used as fallback, and their errors are reported to parser as warnings.
+// Loading fails only if all sources are failed
+type ChainSource struct {
+	name    string
+	sources []Source
+	mu      sync.Mutex
+	origins map[string]string // Keys - config names, values - names of sources that provided them
+}
+
+// Create chain of sources in order of priority
+func NewChainSource(name string, sources ...Source) *ChainSource {
+	return &ChainSource{name: name, sources: sources}
+}
+
+func (s *ChainSource) Name() string {
+	return s.name
+}
+
+// Load all sources of chain and merge them, so values of earlier sources win. When chain is loaded by parser,
+// its sources are loaded with their own settings (WithSourceTimeout, WithSourceRetry)
+func (s *ChainSource) Load(ctx context.Context) (map[string]string, error) {
+	result := make(map[string]string)
+	origins := make(map[string]string)
+	failures := []error{}
+
+	for _, src := range s.sources {
+		values, err := loadWithSettings(ctx, src)
+		if err != nil {
+			failures = append(failures, err)
+			continue
+		}
+
+		for k, v := range values {
+			if _, ok := result[k]; ok {
+				continue
+			}
+			result[k] = v
+			origins[k] = src.Name()
+		}
+	}
+
+	if len(s.sources) > 0 && len(failures) == len(s.sources) {
+		messages := make([]string, len(failures))
+		for i, failure := range failures {
+			messages[i] = failure.Error()
+		}
+		return nil, errors.New(fmt.Sprintf("All sources of chain are failed: %s", strings.Join(messages, "; ")))
+	}
+	for _, failure := range failures {
+		reportSourceWarning(ctx, fmt.Errorf("Source of chain %s is skipped: %w", s.name, failure))
+	}
+
+	s.mu.Lock()
+	s.origins = origins
+	s.mu.Unlock()
+
+	return result, nil
+}
+
+// Return name of source that provided config name with last Load
+func (s *ChainSource) Origin(name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	origin, ok := s.origins[name]
+	return origin, ok
+}
+
+// Return names of sources that provided each config name with last Load
+func (s *ChainSource) Origins() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return copyValues(s.origins)
+}
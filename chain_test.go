@@ -0,0 +1,97 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChainSource_Load(t *testing.T) {
+	vault := &staticSource{name: "vault", values: map[string]string{"db.pass": "vault"}}
+	ssm := &staticSource{name: "ssm", values: map[string]string{"db.pass": "ssm", "db.user": "ssm"}}
+	file := &staticSource{name: "file", values: map[string]string{"db.user": "file", "db.host": "file"}}
+	broken := &staticSource{name: "broken", err: errors.New("unavailable")}
+
+	tests := []struct {
+		name        string
+		sources     []Source
+		want        map[string]string
+		wantOrigins map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "priority",
+			sources:     []Source{vault, ssm, file},
+			want:        map[string]string{"db.pass": "vault", "db.user": "ssm", "db.host": "file"},
+			wantOrigins: map[string]string{"db.pass": "vault", "db.user": "ssm", "db.host": "file"},
+		},
+		{
+			name:        "fallback",
+			sources:     []Source{broken, ssm, file},
+			want:        map[string]string{"db.pass": "ssm", "db.user": "ssm", "db.host": "file"},
+			wantOrigins: map[string]string{"db.pass": "ssm", "db.user": "ssm", "db.host": "file"},
+		},
+		{
+			name:    "all failed",
+			sources: []Source{broken, broken},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := NewChainSource("secrets", tt.sources...)
+			got, err := chain.Load(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ChainSource.Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChainSource.Load() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(chain.Origins(), tt.wantOrigins) {
+				t.Errorf("ChainSource.Origins() = %v, want %v", chain.Origins(), tt.wantOrigins)
+			}
+			if origin, ok := chain.Origin("db.host"); !ok || origin != "file" {
+				t.Errorf("ChainSource.Origin() = %v, %v, want %v", origin, ok, "file")
+			}
+		})
+	}
+}
+
+func TestParser_fetchSources_chain(t *testing.T) {
+	flaky := &flakySource{failures: 1}
+	file := &staticSource{name: "file", values: map[string]string{"a": "file", "db.host": "file"}}
+	chain := NewChainSource("secrets", &hangingSource{}, flaky, file)
+
+	p := &Parser{}
+	for _, opt := range []Option{
+		WithSource(NewCachedSource(chain, time.Minute)),
+		WithSourceTimeout("hanging", 10*time.Millisecond),
+		WithSourceRetry("flaky", 1, time.Millisecond),
+	} {
+		opt(p)
+	}
+
+	values, origins, warnings, err := p.fetchSources(true)
+	if err != nil {
+		t.Fatalf("Parser.fetchSources() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0].Error(), "Source of chain secrets is skipped: hanging: ") {
+		t.Errorf("Parser.fetchSources() warnings = %v, want skipped hanging source", warnings)
+	}
+	if want := map[string]string{"a": "1", "db.host": "file"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("Parser.fetchSources() = %v, want %v", values, want)
+	}
+	if want := map[string]string{"a": "flaky", "db.host": "file"}; !reflect.DeepEqual(origins, want) {
+		t.Errorf("Parser.fetchSources() origins = %v, want %v", origins, want)
+	}
+	if flaky.loads != 2 {
+		t.Errorf("Parser.fetchSources() loads of chain member = %v, want %v", flaky.loads, 2)
+	}
+}
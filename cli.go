@@ -0,0 +1,194 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Return positional command-line arguments of last Parse in their order: arguments that are not flags or their
+// values, and all arguments after "--" terminator. Ex.: subcommand or list of files
+func (p *Parser) Args() []string {
+	if p.mu != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+	}
+
+	return append([]string{}, p.args...)
+}
+
+// Replace @file arguments (except program name) with arguments read from file: one argument per line,
+// empty lines and lines starting with "#" are skipped. Arguments after "--" terminator are kept as is
+func expandResponseFiles(args []string) ([]string, error) {
+	result := []string{}
+	for i, arg := range args {
+		if argsTerminator == arg {
+			return append(result, args[i:]...), nil
+		}
+		if i == 0 || !strings.HasPrefix(arg, responseFilePrefix) || arg == responseFilePrefix {
+			result = append(result, arg)
+			continue
+		}
+
+		content, err := os.ReadFile(strings.TrimPrefix(arg, responseFilePrefix))
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Can't read arguments file %s: %s", arg, err))
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			result = append(result, line)
+		}
+	}
+
+	return result, nil
+}
+
+// Prefix of cli name that sets bool config to false. Ex.: --no-verbose
+const negationPrefix = "no-"
+
+// Check if cli name is negation of bool config (or its alias). Return negated name.
+// Name that exactly matches some config is not treated as negation
+func (p *Parser) negatedBoolName(name string) (string, bool) {
+	if !strings.HasPrefix(name, negationPrefix) || p.fieldByCliName(name) != nil {
+		return "", false
+	}
+
+	negated := strings.TrimPrefix(name, negationPrefix)
+	field := p.fieldByCliName(negated)
+	if field == nil {
+		return "", false
+	}
+	if t := p.fieldType(field); t == nil || t.Kind() != reflect.Bool {
+		return "", false
+	}
+
+	return negated, true
+}
+
+// Check if cli flag (with dashes) is occurrence of count field. Return name of field and number of occurrences:
+// repeated short name counts each letter. Ex.: "-vvv" gives 3
+func (p *Parser) countedName(flag string) (string, int, bool) {
+	if strings.HasPrefix(flag, "--") {
+		field := p.fieldByCliName(strings.TrimPrefix(flag, "--"))
+		if field == nil || !field.tags.count {
+			return "", 0, false
+		}
+		return field.tags.cliName(), 1, true
+	}
+
+	letters := strings.TrimPrefix(flag, "-")
+	if letters == "" {
+		return "", 0, false
+	}
+	short := string([]rune(letters)[0])
+	if strings.Repeat(short, len([]rune(letters))) != letters {
+		return "", 0, false
+	}
+	for _, field := range p.fields {
+		if field.tags.count && field.tags.short == short {
+			return field.tags.cliName(), len([]rune(letters)), true
+		}
+	}
+
+	return "", 0, false
+}
+
+// Check if cli flag without "=" needs value from next argument: all configs except bool ones do.
+// Second result is false if there is no config with such name
+func (p *Parser) takesValue(name string) (bool, bool) {
+	field := p.fieldByCliName(name)
+	if field == nil {
+		return false, false
+	}
+	t := p.fieldType(field)
+	if t == nil {
+		return false, false
+	}
+
+	return t.Kind() != reflect.Bool, true
+}
+
+// Check if cli argument is flag. Negative number is value, unless some config uses it as short or long name.
+// Ex.: "--offset -5"
+func (p *Parser) isFlag(arg string) bool {
+	if !strings.HasPrefix(arg, "-") {
+		return false
+	}
+	if !isNegativeNumber(arg) {
+		return true
+	}
+
+	name := strings.TrimLeft(arg, "-")
+	if _, ok := p.shortNames()[name]; ok {
+		return true
+	}
+
+	return p.fieldByCliName(name) != nil
+}
+
+// Check if cli argument is negative number (integer, float or hex). Ex.: "-5", "-0.5", "-1e3", "-0x10"
+func isNegativeNumber(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' || !(arg[1] >= '0' && arg[1] <= '9' || arg[1] == '.') {
+		return false
+	}
+	if _, err := strconv.ParseFloat(arg, 64); err == nil {
+		return true
+	}
+	_, err := strconv.ParseInt(arg, 0, 64)
+
+	return err == nil
+}
+
+// Find field by cli name (value of cliname tag or config name) or alias. Return nil if there is no such field
+func (p *Parser) fieldByCliName(name string) *structField {
+	for _, field := range p.fields {
+		if p.sameKey(field.tags.cliName(), name) {
+			return field
+		}
+		for _, alias := range field.tags.aliases {
+			if p.sameKey(alias, name) {
+				return field
+			}
+		}
+	}
+
+	return nil
+}
+
+// Return map of short cli names into config names
+func (p *Parser) shortNames() map[string]string {
+	result := make(map[string]string)
+	for _, field := range p.fields {
+		if field.tags.short != "" {
+			result[field.tags.short] = field.tags.cliName()
+		}
+	}
+
+	return result
+}
+
+// Check that short names are unique and don't shadow config names
+func (p *Parser) checkShortNames() error {
+	seen := make(map[string]string)
+	for _, field := range p.fields {
+		short := field.tags.short
+		if short == "" {
+			continue
+		}
+		if other, ok := seen[short]; ok {
+			return errors.New(fmt.Sprintf("Short name -%s is used by both %s and %s", short, other, field.tags.name))
+		}
+		if other := p.fieldByConfigName(short); other != nil {
+			return errors.New(fmt.Sprintf("Short name -%s of %s collides with config %s", short, field.tags.name, short))
+		}
+		seen[short] = field.tags.name
+	}
+
+	return nil
+}
@@ -0,0 +1,426 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParser_parseCli_short(t *testing.T) {
+	type testStruct struct {
+		Verbose bool   `config:"name:verbose;mode:cli;short:v;desc:Verbose output"`
+		Output  string `config:"name:output;mode:cli;short:o;desc:Output file"`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.parseCli([]string{"/app/test", "-v=t", "-o", "out.txt", "--o=long"})
+	want := map[string]string{"verbose": "t", "output": "out.txt", "o": "long"}
+	if !reflect.DeepEqual(p.parsedCli, want) {
+		t.Errorf("Parser.parseCli() = %v, want %v", p.parsedCli, want)
+	}
+
+	wantHelp := "-o, --output  Output file (cli only)\n-v, --verbose Verbose output (cli only)\n"
+	if got := p.Help(""); got != wantHelp {
+		t.Errorf("Parser.Help() = \n%v\n, want \n%v\n", got, wantHelp)
+	}
+}
+
+func TestNewParser_shortCollision(t *testing.T) {
+	var duplicate struct {
+		Verbose bool `config:"name:verbose;short:v"`
+		Version bool `config:"name:version;short:v"`
+	}
+	if _, err := NewParser(&duplicate); err == nil {
+		t.Errorf("NewParser() should fail with duplicated short name")
+	}
+
+	var shadow struct {
+		Verbose bool `config:"name:verbose;short:x"`
+		X       int  `config:"name:x"`
+	}
+	if _, err := NewParser(&shadow); err == nil {
+		t.Errorf("NewParser() should fail with short name equal to config name")
+	}
+
+	var long struct {
+		Verbose bool `config:"name:verbose;short:vv"`
+	}
+	if _, err := NewParser(&long); err == nil {
+		t.Errorf("NewParser() should fail with long short name")
+	}
+}
+
+func TestParser_Parse_alias(t *testing.T) {
+	type testStruct struct {
+		Name   string `config:"name:new_name;alias:old-name,legacy_name"`
+		Prefix string `config:"name:prefix;mode:cli"`
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		env        map[string]string
+		want       string
+		wantSource string
+	}{
+		{name: "name", args: []string{"/app/test", "--new_name=a", "--old-name=b"}, want: "a", wantSource: "cli"},
+		{name: "alias", args: []string{"/app/test", "--legacy_name=c"}, want: "c", wantSource: "cli"},
+		{name: "aliases order", args: []string{"/app/test", "--legacy_name=c", "--old-name=b"}, want: "b", wantSource: "cli"},
+		{name: "env alias", args: []string{"/app/test"}, env: map[string]string{"LEGACY_NAME": "d"}, want: "d", wantSource: "env"},
+		{name: "source priority", args: []string{"/app/test", "--old-name=b"}, env: map[string]string{"NEW_NAME": "d"}, want: "b", wantSource: "cli"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = tt.args
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			var cfg testStruct
+			p, err := NewParser(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = p.Parse("", "prefix"); err != nil {
+				t.Fatalf("Parser.Parse() error = %v", err)
+			}
+			if cfg.Name != tt.want {
+				t.Errorf("Parser.Parse() = %v, want %v", cfg.Name, tt.want)
+			}
+			if got := p.Snapshot().Sources["new_name"]; got != tt.wantSource {
+				t.Errorf("Parser.Parse() source = %v, want %v", got, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestParser_parseCli_negation(t *testing.T) {
+	type testStruct struct {
+		Verbose bool   `config:"name:verbose;mode:cli;default:true"`
+		Color   bool   `config:"name:color;alias:colour"`
+		NoCache bool   `config:"name:no-cache"`
+		Name    string `config:"name:name"`
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want map[string]string
+	}{
+		{name: "negation", args: []string{"/app/test", "--no-verbose", "file"}, want: map[string]string{"verbose": "false"}},
+		{name: "alias", args: []string{"/app/test", "--no-colour"}, want: map[string]string{"colour": "false"}},
+		{name: "config with prefix", args: []string{"/app/test", "--no-cache"}, want: map[string]string{"no-cache": "true"}},
+		{name: "not bool", args: []string{"/app/test", "--no-name"}, want: map[string]string{"no-name": ""}},
+		{name: "with value", args: []string{"/app/test", "--no-verbose=t"}, want: map[string]string{"no-verbose": "t"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg testStruct
+			p, err := NewParser(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.parseCli(tt.args)
+			if !reflect.DeepEqual(p.parsedCli, tt.want) {
+				t.Errorf("Parser.parseCli() = %v, want %v", p.parsedCli, tt.want)
+			}
+		})
+	}
+
+	var cfg testStruct
+	p, _ := NewParser(&cfg)
+	p.parseCli([]string{"/app/test", "--no-verbose", "--no-colour"})
+	if err := p.fillStructWithValues(&cfg, ""); err != nil || cfg.Verbose || cfg.Color {
+		t.Errorf("Parser.fillStructWithValues() = %+v, error = %v", cfg, err)
+	}
+}
+
+func TestParser_parseCli_count(t *testing.T) {
+	type testStruct struct {
+		Verbose int    `config:"name:verbose;mode:cli;short:v;count"`
+		Output  string `config:"name:output;mode:cli;short:o"`
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "not set", args: []string{"/app/test"}, want: 0},
+		{name: "single", args: []string{"/app/test", "-v"}, want: 1},
+		{name: "repeated", args: []string{"/app/test", "-v", "--verbose", "-v", "file"}, want: 3},
+		{name: "combined", args: []string{"/app/test", "-vvv", "-v"}, want: 4},
+		{name: "value", args: []string{"/app/test", "--verbose=2"}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg testStruct
+			p, err := NewParser(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.parseCli(tt.args)
+			if err = p.fillStructWithValues(&cfg, ""); err != nil {
+				t.Fatalf("Parser.fillStructWithValues() error = %v", err)
+			}
+			if cfg.Verbose != tt.want {
+				t.Errorf("Parser.parseCli() verbose = %v, want %v", cfg.Verbose, tt.want)
+			}
+			if cfg.Output != "" {
+				t.Errorf("Parser.parseCli() output = %v, want empty", cfg.Output)
+			}
+		})
+	}
+
+	var wrong struct {
+		Verbose bool `config:"name:verbose;count"`
+	}
+	if _, err := NewParser(&wrong); err == nil {
+		t.Errorf("NewParser() should fail with count tag on bool field")
+	}
+}
+
+func TestParser_parseCli_negative(t *testing.T) {
+	type testStruct struct {
+		Offset int     `config:"name:offset;mode:cli"`
+		Scale  float64 `config:"name:scale;mode:cli;short:s"`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.parseCli([]string{"/app/test", "--offset", "-5", "-s", "-1e3", "-7"})
+	if err = p.fillStructWithValues(&cfg, ""); err != nil {
+		t.Fatalf("Parser.fillStructWithValues() error = %v", err)
+	}
+	want := testStruct{Offset: -5, Scale: -1000}
+	if cfg != want {
+		t.Errorf("Parser.parseCli() = %+v, want %+v", cfg, want)
+	}
+}
+
+func Test_isNegativeNumber(t *testing.T) {
+	tests := map[string]bool{"-5": true, "-0.5": true, "-.5": true, "-1e3": true, "-0x10": true, "5": false, "-": false, "-v": false, "--5": false, "-inf": false, "-5s": false}
+	for arg, want := range tests {
+		if got := isNegativeNumber(arg); got != want {
+			t.Errorf("isNegativeNumber(%q) = %v, want %v", arg, got, want)
+		}
+	}
+}
+
+func TestParser_parseCli_typed(t *testing.T) {
+	type testStruct struct {
+		Verbose bool   `config:"name:verbose;mode:cli;short:v"`
+		Name    string `config:"name:name;mode:cli;short:n"`
+		Output  string `config:"name:output;mode:cli"`
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		want     testStruct
+		wantArgs []string
+	}{
+		{name: "bool before positional", args: []string{"/app/test", "--verbose", "file.txt"}, want: testStruct{Verbose: true}, wantArgs: []string{"file.txt"}},
+		{name: "short bool before positional", args: []string{"/app/test", "-v", "file.txt", "--name", "app"}, want: testStruct{Verbose: true, Name: "app"}},
+		{name: "value starting with dash", args: []string{"/app/test", "--name", "-app", "-v"}, want: testStruct{Verbose: true, Name: "-app"}},
+		{name: "value looking like flag", args: []string{"/app/test", "-n", "--verbose"}, want: testStruct{Name: "--verbose"}},
+		{name: "missing value", args: []string{"/app/test", "--output"}, want: testStruct{}},
+		{name: "terminator", args: []string{"/app/test", "--verbose", "--", "--name"}, want: testStruct{Verbose: true}, wantArgs: []string{"--name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg testStruct
+			p, err := NewParser(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.parseCli(tt.args)
+			if err = p.fillStructWithValues(&cfg, ""); err != nil {
+				t.Fatalf("Parser.fillStructWithValues() error = %v", err)
+			}
+			if cfg != tt.want {
+				t.Errorf("Parser.parseCli() = %+v, want %+v", cfg, tt.want)
+			}
+			if tt.wantArgs != nil && !reflect.DeepEqual(p.Args(), tt.wantArgs) {
+				t.Errorf("Parser.Args() = %v, want %v", p.Args(), tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestParser_parseCli_responseFile(t *testing.T) {
+	type testStruct struct {
+		Name   string `config:"name:name;mode:cli"`
+		Port   int    `config:"name:port;mode:cli"`
+		Labels string `config:"name:labels;mode:cli"`
+	}
+
+	path := filepath.Join(t.TempDir(), "args.txt")
+	err := os.WriteFile(path, []byte("# Generated arguments\n--name\n  my app  \n\n--port=8080\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.parseCli([]string{"/app/test", "@" + path, "--labels=@team", "--", "@" + path}); err != nil {
+		t.Fatalf("Parser.parseCli() error = %v", err)
+	}
+	want := map[string]string{"name": "my app", "port": "8080", "labels": "@team"}
+	if !reflect.DeepEqual(p.parsedCli, want) {
+		t.Errorf("Parser.parseCli() = %v, want %v", p.parsedCli, want)
+	}
+	if want := []string{"@" + path}; !reflect.DeepEqual(p.Args(), want) {
+		t.Errorf("Parser.Args() = %v, want %v", p.Args(), want)
+	}
+
+	if err = p.parseCli([]string{"/app/test", "@" + path + ".missing"}); err == nil {
+		t.Errorf("Parser.parseCli() should fail with missing arguments file")
+	}
+}
+
+func TestParser_Parse_sourceNames(t *testing.T) {
+	type testStruct struct {
+		Config string `config:"name:config;mode:cli"`
+		DbURL  string `config:"name:db_url;cliname:db-url;envname:DATABASE_URL;cfgname:database.url;short:d;desc:Database url"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"database":{"url":"cfg"},"db_url":"zzz"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		env  string
+		want string
+	}{
+		{name: "env", args: []string{"/app/test", "--db_url=zzz"}, env: "env", want: "env"},
+		{name: "cfg", args: []string{"/app/test", "--config=" + path}, env: "env", want: "cfg"},
+		{name: "cli", args: []string{"/app/test", "--config=" + path, "--db-url", "cli"}, env: "env", want: "cli"},
+		{name: "short", args: []string{"/app/test", "-d", "short"}, want: "short"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = tt.args
+			t.Setenv("DATABASE_URL", tt.env)
+			t.Setenv("DB_URL", "zzz")
+
+			var cfg testStruct
+			p, err := NewParser(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = p.Parse("config", ""); err != nil {
+				t.Fatalf("Parser.Parse() error = %v", err)
+			}
+			if cfg.DbURL != tt.want {
+				t.Errorf("Parser.Parse() = %v, want %v", cfg.DbURL, tt.want)
+			}
+		})
+	}
+
+	var cfg testStruct
+	p, _ := NewParser(&cfg)
+	if got := p.expectedSources(p.fields["DbURL"]); got != "cli --db-url, cfg database.url, env DATABASE_URL" {
+		t.Errorf("Parser.expectedSources() = %v", got)
+	}
+	if got := p.Help(""); got != "-d, --db-url Database url\n" {
+		t.Errorf("Parser.Help() = %q", got)
+	}
+}
+
+func TestParser_Parse_nestedFlags(t *testing.T) {
+	type testStruct struct {
+		DB struct {
+			Host    string `config:"name:host;default:localhost;desc:Database host"`
+			Verbose bool   `config:"name:verbose;desc:Log queries"`
+			Pool    struct {
+				Size int `config:"name:size;desc:Pool size"`
+			} `config:"name:pool"`
+		} `config:"name:db;mode:cli"`
+	}
+
+	os.Args = []string{"/app/test", "--db.host", "db.local", "--db.verbose", "--db.pool.size=10", "file"}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if cfg.DB.Host != "db.local" || !cfg.DB.Verbose || cfg.DB.Pool.Size != 10 {
+		t.Errorf("Parser.Parse() = %+v", cfg)
+	}
+	if got := p.Args(); !reflect.DeepEqual(got, []string{"file"}) {
+		t.Errorf("Parser.Args() = %v", got)
+	}
+
+	want := "--db.host[=localhost] Database host (cli only)\n" +
+		"--db.pool.size        Pool size (cli only)\n" +
+		"--db.verbose          Log queries (cli only)\n"
+	if got := p.Help(""); got != want {
+		t.Errorf("Parser.Help() = %q, want %q", got, want)
+	}
+}
+
+func TestParser_Parse_aliasChain(t *testing.T) {
+	type testStruct struct {
+		Config string `config:"name:config;mode:cli"`
+		DB     struct {
+			Host string `config:"name:host;alias:hostname,server"`
+		} `config:"name:db"`
+	}
+
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		want string
+	}{
+		{name: "name", file: `{"db":{"host":"a","hostname":"b","server":"c"}}`, want: "a"},
+		{name: "first alias", file: `{"db":{"server":"c","hostname":"b"}}`, want: "b"},
+		{name: "last alias", file: `{"db":{"server":"c"}}`, want: "c"},
+		{name: "file over env", file: `{"db":{"server":"c"}}`, env: map[string]string{"DB_HOST": "env"}, want: "c"},
+		{name: "env alias", file: `{}`, env: map[string]string{"DB_SERVER": "env"}, want: "env"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.file), 0644); err != nil {
+				t.Fatal(err)
+			}
+			os.Args = []string{"/app/test", "--config=" + path}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			var cfg testStruct
+			p, err := NewParser(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = p.Parse("config", ""); err != nil {
+				t.Fatalf("Parser.Parse() error = %v", err)
+			}
+			if cfg.DB.Host != tt.want {
+				t.Errorf("Parser.Parse() = %v, want %v", cfg.DB.Host, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// When help is colored
+type ColorMode int
+
+const (
+	ColorOff    ColorMode = iota // Never. Default
+	ColorAuto                    // If writer is terminal and NO_COLOR env variable is not set
+	ColorAlways                  // Always, even if writer is file or pipe
+)
+
+// ANSI escape sequences of help colors
+const (
+	colorBold   = "\x1b[1m"
+	colorCyan   = "\x1b[36m"
+	colorReset  = "\x1b[0m"
+	noColorName = "NO_COLOR"
+)
+
+// Set when flags and defaults are colored in help written by WriteHelp, WriteUsage and auto help
+func WithColor(mode ColorMode) Option {
+	return func(p *Parser) {
+		p.color = mode
+	}
+}
+
+// Check if help written into writer should be colored
+func (p *Parser) useColor(w io.Writer) bool {
+	switch p.color {
+	case ColorAlways:
+		return true
+	case ColorAuto:
+		if os.Getenv(noColorName) != "" {
+			return false
+		}
+		file, ok := w.(*os.File)
+		return ok && terminalWidth(file) > 0
+	}
+
+	return false
+}
+
+// Make flag names of help entry bold and its default value cyan. Ex.: "-p, --port[=80]"
+func colorFlag(flag string) string {
+	names, defaultValue, hasDefault := strings.Cut(flag, "[=")
+	result := colorBold + names + colorReset
+	if hasDefault {
+		result = result + "[=" + colorCyan + strings.TrimSuffix(defaultValue, "]") + colorReset + "]"
+	}
+
+	return result
+}
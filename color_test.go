@@ -0,0 +1,61 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestWithColor(t *testing.T) {
+	type testStruct struct {
+		Port  int    `config:"name:port;mode:cli;short:p;default:80;desc:Port to listen"`
+		Level string `config:"name:level;mode:cli;desc:Log level"`
+	}
+
+	tests := []struct {
+		name string
+		mode ColorMode
+		want string
+	}{
+		{name: "off", mode: ColorOff, want: "--level         Log level (cli only)\n-p, --port[=80] Port to listen (cli only)\n"},
+		{name: "auto", mode: ColorAuto, want: "--level         Log level (cli only)\n-p, --port[=80] Port to listen (cli only)\n"},
+		{name: "always", mode: ColorAlways, want: "\x1b[1m--level\x1b[0m         Log level (cli only)\n\x1b[1m-p, --port\x1b[0m[=\x1b[36m80\x1b[0m] Port to listen (cli only)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COLUMNS", "")
+
+			var cfg testStruct
+			p, err := NewParser(&cfg, WithColor(tt.mode))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer // Not terminal
+			if err = p.WriteHelp(&buf, ""); err != nil {
+				t.Fatalf("Parser.WriteHelp() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Parser.WriteHelp() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_useColor(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	p := Parser{color: ColorAuto}
+	if p.useColor(file) {
+		t.Errorf("Parser.useColor() = true for regular file")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	p.color = ColorAlways
+	if !p.useColor(file) {
+		t.Errorf("Parser.useColor() = false with ColorAlways")
+	}
+}
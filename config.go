@@ -1,30 +1,109 @@
 package config
 
 import (
-	"bytes"
+	"crypto/tls"
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"golang.org/x/exp/maps"
 )
 
 // Struct where stored all received and parsed values
 type Parser struct {
-	in        interface{}
-	fields    map[string]*structField
-	envPrefix string
-	parsedCfg map[string]string // File
-	parsedCli map[string]string // Command-line args
+	in         interface{}
+	fields     map[string]*structField
+	envPrefix  string
+	parsedCfg  map[string]string // File
+	parsedCli  map[string]string // Command-line args
+	args       []string          // Positional command-line args
+	parsedEnv  map[string]string // Env values read from files by NAME_FILE env variables
+	parsedFile map[string]string // Config file values, before merging external sources into parsedCfg
+	cfgOrigins map[string]string // Keys - names from parsedCfg, values - names of external sources that provided them
+
+	envPrefixes        []string // Env prefixes tried in order after one set by Parse. Ex.: old prefix of renamed service
+	envNestedSeparator string   // Replacement of nested separator in env variable names. Default is "_"
+	envListSeparator   string   // Separator of list items in env values, if field has no sep tag
+	envJSON            string   // Name of env variable with json document, parsed like config file
+	envCase            EnvCase  // Case of env variable names. Default is upper
+
+	lookupEnvFunc func(key string) (string, bool) // Replacement of os.LookupEnv
+	snapshotEnv   bool                            // Env is captured once by Parse and Reload
+	envSnapshot   map[string]string               // Env captured by last Parse or Reload, if snapshotEnv is set
+
+	useDotenv     bool              // Env variables are loaded from dotenv files too
+	dotenvDir     string            // Directory of dotenv files. Default is working directory
+	dotenvProfile string            // Profile of dotenv files. Ex.: "prod" for .env.prod
+	dotenv        map[string]string // Values of dotenv files, used if env variable is not set
+
+	httpTimeout time.Duration     // Timeout for fetching config by url
+	httpHeaders map[string]string // Extra headers for fetching config by url
+	httpTLS     *tls.Config       // TLS settings for fetching config by url
+
+	sources        []Source                 // External providers of config values (Consul, etc.)
+	sourceTimeouts map[string]time.Duration // Keys - source names
+	sourceRetries  map[string]retryPolicy   // Keys - source names
+	sourceCaches   map[string]string        // Keys - source names, values - fallback cache file paths
+
+	lenientSources     bool            // Failed sources don't stop parsing
+	lenientSourceNames map[string]bool // Lenient sources. All sources are lenient if empty
+
+	pollInterval time.Duration // Interval of external sources re-fetching
+	pollStop     chan struct{} // Closed to stop polling
+	onChange     func()        // Handler of config changes found by polling
+	watch        bool          // Sources that support it are watched after Parse
+	onRefill     []func()      // Handlers called under lock after each successful filling of config struct
+
+	mu              *sync.Mutex       // Guards parsing and refilling of config struct
+	isParsed        bool              // Parse was called
+	cfgPathConfig   string            // Settings of last Parse call, used by Reload
+	envPrefixConfig string            // Settings of last Parse call, used by Reload
+	values          map[string]string // Keys - config names, values - raw values written into config struct
+	valueSources    map[string]string // Keys - config names, values - names of sources of values
+	valueKeys       map[string]string // Keys - config names, values - keys of values in their sources
+	initial         reflect.Value     // Copy of config struct before first parsing
+	onWarning       func(error)       // Handler of non-fatal problems
+	warnings        []error           // Non-fatal problems of last parsing
+
+	validators map[string]func(value interface{}) error // Keys - names used in validate tag
+
+	jsonTags     bool                    // Fields with json tag but without config tag are parsed too
+	normalizeKey func(key string) string // Applied to keys of all sources and to names of fields before comparing
+	nestedSep    string                  // Separator of nested config names. Default is "."
+	cfgSection   string                  // Path of config file object with values of this parser. Ex.: "services.api"
+	strictStruct bool                    // Exported fields without config tag are errors
+	nilStructs   map[string]bool         // Paths of pointers to nested structs left nil by last filling, because no values were set
+
+	autoHelp   bool      // Parse handles --help and -h
+	helpWriter io.Writer // Destination of auto help. Default is os.Stdout
+
+	helpTemplate    *template.Template // Template of usage text. Default is usage line and options
+	helpSourceNames bool               // Help lists config file key and env variable of each option
+	color           ColorMode          // When help is colored. Default is never
+
+	declarationOrder bool // Help, docs and Fields list fields in struct declaration order
+
+	onTrace func(trace []FieldTrace) // Handler of resolution trace of each filling
+	trace   []*FieldTrace            // Trace of current filling
 }
 
+// Optional setting of parser. Should be passed to NewParser
+type Option func(*Parser)
+
 // Each field of received config struct has own instance
 type structField struct {
 	name string
@@ -39,6 +118,31 @@ type structFieldTags struct {
 	hasDefaultValue bool
 	description     string
 	hasDescription  bool
+	secret          bool
+	separator       string
+	encoding        string
+	unit            string
+	required        bool
+	min             float64
+	hasMin          bool
+	max             float64
+	hasMax          bool
+	oneOf           []string
+	validators      []string
+	requiredIf      map[string]string
+	deprecated      string
+	isDeprecated    bool
+	hidden          bool
+	short           string
+	aliases         []string
+	count           bool
+	env             string
+	noPrefix        bool
+	envCase         EnvCase
+	cli             string
+	cfg             string
+	example         string
+	longDescription string
 }
 
 const (
@@ -48,17 +152,52 @@ const (
 	separatorInner = ":"
 	// Splitter between values list. Ex.: `mode:cli,cfg`
 	separatorList = ","
-	// Separator to use in pathes of nested struct params
+	// Separator to use in pathes of nested struct params. Default separator of their config names
 	separatorNested = "."
+	// Splitter between key and value of map item. Ex.: `env=prod`
+	separatorPair = "="
+	// Command-line argument that ends flags. Ex.: `app --verbose -- -file-with-dash`
+	argsTerminator = "--"
+	// Prefix of command-line argument that is replaced with arguments from file. Ex.: `app @args.txt`
+	responseFilePrefix = "@"
+	// Brackets around index of array item in flattened config file keys. Ex.: `servers[0].host`
+	indexOpen  = "["
+	indexClose = "]"
 )
 
 // Moved to const just to have all of them at one place
 const (
-	tag        = "config"
-	tagName    = "name"
-	tagMode    = "mode"
-	tagDefault = "default"
-	tagDesc    = "desc"
+	tag           = "config"
+	tagName       = "name"
+	tagMode       = "mode"
+	tagDefault    = "default"
+	tagDesc       = "desc"
+	tagSecret     = "secret"
+	tagSep        = "sep"
+	tagEncoding   = "encoding"
+	tagUnit       = "unit"
+	tagRequired   = "required"
+	tagMin        = "min"
+	tagMax        = "max"
+	tagOneOf      = "oneof"
+	tagValidate   = "validate"
+	tagRequiredIf = "required_if"
+	tagDeprecated = "deprecated"
+	tagHidden     = "hidden"
+	tagShort      = "short"
+	tagAlias      = "alias"
+	tagCount      = "count"
+	tagEnv        = "env"
+	tagNoPrefix   = "noprefix"
+	tagEnvCase    = "envcase"
+	tagEnvName    = "envname"
+	tagCliName    = "cliname"
+	tagCfgName    = "cfgname"
+	tagExample    = "example"
+	tagLongDesc   = "longdesc"
+	tagIgnore     = "-" // Whole tag value to exclude field. Ex.: `config:"-"`
+
+	jsonTag = "json" // Tag with fallback names. Ex.: `json:"db_host,omitempty"`
 )
 
 // Available modes where specific param will be looked for
@@ -69,6 +208,14 @@ const (
 	modeAll = 0b111
 )
 
+// Names of value sources, reported by snapshots. External sources are reported by their own names
+const (
+	sourceCli     = "cli"
+	sourceCfg     = "cfg"
+	sourceEnv     = "env"
+	sourceDefault = "default"
+)
+
 // Keys - available modes textual values and flags
 var modes = map[string]int{
 	"cli": modeCli,
@@ -76,6 +223,19 @@ var modes = map[string]int{
 	"env": modeEnv,
 }
 
+// Modes textual values in order of showing in help
+var modesOrder = []string{"cli", "cfg", "env"}
+
+// Types with own parsing rules
+var (
+	durationType = reflect.TypeOf(time.Duration(0)) // Parsed from strings like "1m30s" instead of nanoseconds
+	ipType       = reflect.TypeOf(net.IP{})
+	ipNetType    = reflect.TypeOf(net.IPNet{})
+	regexpType   = reflect.TypeOf(&regexp.Regexp{})
+
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
 // Accepted values for boolean fields.
 // While compare given value will be lowercased
 var boolValues = map[bool][]string{
@@ -83,8 +243,16 @@ var boolValues = map[bool][]string{
 	false: {"false", "f", "n", "no"},
 }
 
+// Set handler of non-fatal problems found while parsing (ex.: source is unavailable and cached values are used).
+// By default they are ignored
+func WithWarningHandler(handler func(error)) Option {
+	return func(p *Parser) {
+		p.onWarning = handler
+	}
+}
+
 // Create new instance of parser for specific config struct.
-func NewParser(in interface{}) (Parser, error) {
+func NewParser(in interface{}, opts ...Option) (Parser, error) {
 	if reflect.Pointer != reflect.ValueOf(in).Type().Kind() {
 		return Parser{}, errors.New("in should be a pointer to struct")
 	}
@@ -93,6 +261,9 @@ func NewParser(in interface{}) (Parser, error) {
 		in:     in,
 		fields: make(map[string]*structField),
 	}
+	for _, opt := range opts {
+		opt(&p)
+	}
 
 	// Parse struct into fields with tags
 	s := reflect.ValueOf(p.in).Elem()
@@ -104,83 +275,94 @@ func NewParser(in interface{}) (Parser, error) {
 		}
 	}
 
+	err := p.checkShortNames()
+	if err != nil {
+		return Parser{}, err
+	}
+
 	return p, nil
 }
 
 // Return string with formatted and sorted usage hint
 func (p *Parser) Help(prefix string) string {
-	longestParameter := 0
-	fieldsHelp := [][2]string{}
+	return p.HelpWrapped(prefix, 0)
+}
 
-	for _, field := range p.fields {
-		if !field.tags.hasDescription {
-			continue
-		}
+// Execute parsing from all available sources
+// Set cfgPathConfig if you use config file
+// Set envPrefixConfig if you use environment variables and they have project-specific prefix.
+func (p *Parser) Parse(cfgPathConfig, envPrefixConfig string) error {
+	if p.mu == nil {
+		p.mu = &sync.Mutex{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-		defaultHint := ""
-		if field.tags.hasDefaultValue {
-			defaultHint = fmt.Sprintf("[=%s]", field.tags.defaultValue)
-		}
-		var leftPart = fmt.Sprintf("--%s%s", field.tags.name, defaultHint)
-		var rightPart = field.tags.description
-		if field.tags.mode > 0 && field.tags.mode < modeAll {
-			fieldModes := []string{}
-			for title, mode := range modes {
-				if field.tags.mode&mode > 0 {
-					fieldModes = append(fieldModes, title)
-				}
-			}
-			if len(fieldModes) > 0 {
-				if len(rightPart) > 0 {
-					rightPart = rightPart + " "
-				}
-				rightPart = fmt.Sprintf("%s(%s only)", rightPart, strings.Join(fieldModes, ", "))
-			}
-		}
-		fieldsHelp = append(fieldsHelp, [2]string{
-			leftPart,
-			rightPart,
-		})
+	p.cfgPathConfig = cfgPathConfig
+	p.envPrefixConfig = envPrefixConfig
+	p.isParsed = true
 
-		if len(leftPart) > longestParameter {
-			longestParameter = len(leftPart)
-		}
+	_, err := p.parse()
+	if err != nil {
+		return err
 	}
 
-	sort.Slice(fieldsHelp, func(i, j int) bool {
-		return sort.StringsAreSorted([]string{fieldsHelp[i][0], fieldsHelp[j][0]})
-	})
+	p.startPolling()
+
+	return nil
+}
 
-	buffer := bytes.NewBufferString("")
-	for _, field := range fieldsHelp {
-		buffer.WriteString(fmt.Sprintf("%s%-*s %s\n", prefix, longestParameter, field[0], field[1]))
+// Read all sources and fill config struct. Return names of changed configs. Values read from sources are kept
+// just if parsing is succeeded. Should be called under lock
+func (p *Parser) parse() ([]string, error) {
+	state := p.saveParseState()
+	changed, err := p.readAndFill()
+	if err != nil {
+		p.restoreParseState(state)
+		return nil, err
 	}
 
-	return buffer.String()
+	return changed, nil
 }
 
-// Execute parsing from all available sources
-// Set cfgPathConfig if you use config file
-// Set envPrefixConfig if you use environment variables and they have project-specific prefix.
-func (p *Parser) Parse(cfgPathConfig, envPrefixConfig string) error {
-	p.parseCli(os.Args)
+// Read all sources and fill config struct. Return names of changed configs. Should be called under lock
+func (p *Parser) readAndFill() ([]string, error) {
+	p.warnings = nil
+	p.parsedCfg = nil
+	p.cfgOrigins = nil
+	p.captureEnv()
+	err := p.parseCli(os.Args)
+	if err != nil {
+		return nil, err
+	}
+	if p.autoHelp && p.helpRequested() {
+		err = p.printHelp()
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrHelpRequested
+	}
+	err = p.parseDotenv()
+	if err != nil {
+		return nil, err
+	}
 
 	// Special configs that should be loaded just from cli and firstly
 	for _, field := range p.fields {
-		if cfgPathConfig == field.tags.name {
+		if p.cfgPathConfig == field.tags.name {
 			if val, ok := p.getConfig(field.tags.name, field.tags.mode); ok {
 				err := p.parseCfg(val)
 				if err != nil {
-					return err
+					return nil, err
 				}
 			} else if field.tags.hasDefaultValue {
 				err := p.parseCfg(field.tags.defaultValue)
 				if err != nil {
-					return err
+					return nil, err
 				}
 			}
 		}
-		if envPrefixConfig == field.tags.name {
+		if p.envPrefixConfig == field.tags.name {
 			if val, ok := p.getConfig(field.tags.name, field.tags.mode); ok {
 				p.envPrefix = val
 			} else if field.tags.hasDefaultValue {
@@ -189,12 +371,43 @@ func (p *Parser) Parse(cfgPathConfig, envPrefixConfig string) error {
 		}
 	}
 
-	err := p.fillStructWithValues(p.in, "")
+	err = p.parseEnvJSON()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	err = p.parseEnvFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	p.parsedFile = p.parsedCfg
+
+	err = p.loadSources()
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := p.refill()
+	if err != nil {
+		return nil, err
+	}
+	p.warnDeprecated()
+
+	return changed, nil
+}
+
+// Return non-fatal problems found by last Parse call (ex.: failed lenient sources)
+func (p *Parser) Warnings() []error {
+	return p.warnings
+}
+
+// Report non-fatal problem to warning handler
+func (p *Parser) warn(err error) {
+	p.warnings = append(p.warnings, err)
+	if p.onWarning != nil {
+		p.onWarning(err)
+	}
 }
 
 // Recursively go over struct fields and fill fields with their received values
@@ -207,8 +420,11 @@ func (p *Parser) fillStructWithValues(target interface{}, prefix string) error {
 		if prefix != "" {
 			fieldName = fmt.Sprintf("%s%s%s", prefix, separatorNested, fieldName)
 		}
+		if tagIgnore == typeOfT.Field(i).Tag.Get(tag) {
+			continue
+		}
 
-		if field.Type().Kind() == reflect.Struct {
+		if isNestedStruct(field.Type()) && p.fields[fieldName] == nil {
 			newStruct := reflect.New(s.Field(i).Type()).Interface()
 
 			err := p.fillStructWithValues(newStruct, fieldName)
@@ -218,40 +434,144 @@ func (p *Parser) fillStructWithValues(target interface{}, prefix string) error {
 
 			s.Field(i).Set(reflect.ValueOf(newStruct).Elem())
 		}
+		if isNestedStructPointer(field.Type()) && p.fields[fieldName] == nil {
+			err := p.fillStructPointer(field, fieldName)
+			if err != nil {
+				return err
+			}
+		}
 
 		parsedField, _ := p.fields[fieldName]
 		if parsedField == nil {
 			continue
 		}
 
-		value, isSet := p.getConfig(parsedField.tags.name, parsedField.tags.mode)
+		tags := parsedField.tags
+		trace := p.traceField(parsedField)
+		value, source, key, isSet := p.lookupConfig(parsedField.tags.name, parsedField.tags.aliases, parsedField.tags.mode)
+		if !isSet || source == sourceEnv {
+			if items, itemsSource, ok := p.lookupCfgItems(parsedField); ok { // Array items of config file. Ex.: servers[0].host
+				value, source, key, isSet = items, itemsSource, parsedField.tags.cfgName(), true
+				tags.separator = separatorEnvItems
+			}
+		}
+		if !isSet {
+			if items, ok := p.lookupEnvItems(parsedField); ok { // Indexed env variables. Ex.: HOSTS_0, HOSTS_1
+				value, source, key, isSet = items, sourceEnv, p.envKeys(parsedField.tags)[0], true
+				tags.separator = separatorEnvItems
+			}
+		}
 		if !isSet {
 			if parsedField.tags.hasDefaultValue {
 				value = parsedField.tags.defaultValue
+				source = sourceDefault
 			} else {
 				continue
 			}
 		}
 
-		err := p.writeValueToField(field, value)
+		tags = p.sourceTags(tags, source)
+
+		err := p.writeValueToField(field, value, tags)
+		trace.written(field, parsedField.tags, p.shownValue(parsedField, value), source, key, err)
+		if err != nil && tags.secret { // Conversion errors can contain value itself
+			return errors.New(fmt.Sprintf("Wrong value %s of %s (from %s)", redacted, tags.name, source))
+		}
+		if err != nil {
+			return err
+		}
+		err = p.checkLimits(field, tags, value, source)
+		if err != nil {
+			return err
+		}
+		err = p.checkOneOf(field, tags, value, source)
 		if err != nil {
 			return err
 		}
+		err = p.runValidators(field, tags, value, source)
+		if err != nil {
+			return err
+		}
+		if tags.separator == separatorEnvItems {
+			value = strings.ReplaceAll(value, separatorEnvItems, p.sourceTags(parsedField.tags, source).listSeparator())
+		}
+		p.setValue(parsedField.tags.name, value, source, key)
 	}
 
 	return nil
 }
 
+// Fill pointer to nested struct. New struct is set just if some of its fields got value from some source (not default).
+// Otherwise values of its fields are forgotten, and their required checks are skipped
+func (p *Parser) fillStructPointer(field reflect.Value, path string) error {
+	before := copyValues(p.valueSources)
+	newStruct := reflect.New(field.Type().Elem())
+
+	err := p.fillStructWithValues(newStruct.Interface(), path)
+	if err != nil {
+		return err
+	}
+
+	added := []string{}
+	isProvided := false
+	for name, source := range p.valueSources {
+		if _, ok := before[name]; !ok {
+			added = append(added, name)
+			isProvided = isProvided || source != sourceDefault
+		}
+	}
+	if isProvided {
+		field.Set(newStruct)
+		return nil
+	}
+
+	for _, name := range added {
+		delete(p.values, name)
+		delete(p.valueSources, name)
+		delete(p.valueKeys, name)
+	}
+	if p.nilStructs == nil {
+		p.nilStructs = make(map[string]bool)
+	}
+	p.nilStructs[path] = true
+
+	return nil
+}
+
+// Check if field is inside of pointer to nested struct that was left nil by last filling
+func (p *Parser) inNilStruct(field *structField) bool {
+	for path := range p.nilStructs {
+		if strings.HasPrefix(field.name, path+separatorNested) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Generate instance of structField from reflect struct field
 func (p *Parser) newStructField(field reflect.StructField, parent *structField) error {
 	var result = &structField{}
 	result.name = field.Name
 
 	tagValue, ok := field.Tag.Lookup(tag)
-	if !ok {
+	if !ok && p.jsonTags && field.IsExported() && field.Tag.Get(jsonTag) != tagIgnore {
+		_, ok = field.Tag.Lookup(jsonTag) // Field with just json tag is parsed in lenient mode
+	}
+	if !ok && field.Anonymous && field.IsExported() && (isNestedStruct(field.Type) || isNestedStructPointer(field.Type)) {
+		ok = true // Fields of embedded struct are promoted into namespace of parent, like in Go itself
+	}
+	if !ok && p.strictStruct && field.IsExported() {
+		return errors.New(fmt.Sprintf("Field %s has no %s tag. Set `%s:\"%s\"` to skip it", fieldPath(field, parent), tag, tag, tagIgnore))
+	}
+	if !ok || tagIgnore == tagValue {
 		return nil
 	}
+	if !field.IsExported() { // Reflection can't set unexported field
+		return errors.New(fmt.Sprintf("Field %s has %s tag, but it is unexported, so it can't be set", fieldPath(field, parent), tag))
+	}
 
+	var minValue, maxValue string // Limits are parsed after all tags, because they depend on unit
 	tags := strings.Split(tagValue, separator)
 	for _, flag := range tags {
 		tmp := strings.Split(flag, separatorInner)
@@ -276,14 +596,152 @@ func (p *Parser) newStructField(field reflect.StructField, parent *structField)
 		case tagDesc:
 			result.tags.description = fieldTagValue
 			result.tags.hasDescription = true
+		case tagSecret:
+			secret, err := parseBoolTag(fieldTagName, fieldTagValue)
+			if err != nil {
+				return err
+			}
+			result.tags.secret = secret
+		case tagSep:
+			if fieldTagValue == "" {
+				return errors.New(fmt.Sprintf("Empty value of tag %s", tagSep))
+			}
+			result.tags.separator = fieldTagValue
+		case tagEncoding:
+			encoding, err := parseEncodingTag(fieldTagValue)
+			if err != nil {
+				return err
+			}
+			result.tags.encoding = encoding
+		case tagUnit:
+			unit, err := parseUnitTag(fieldTagValue)
+			if err != nil {
+				return err
+			}
+			result.tags.unit = unit
+		case tagRequired:
+			required, err := parseBoolTag(fieldTagName, fieldTagValue)
+			if err != nil {
+				return err
+			}
+			result.tags.required = required
+		case tagMin:
+			minValue = fieldTagValue
+			result.tags.hasMin = true
+		case tagMax:
+			maxValue = fieldTagValue
+			result.tags.hasMax = true
+		case tagOneOf:
+			if fieldTagValue == "" {
+				return errors.New(fmt.Sprintf("Empty value of tag %s", tagOneOf))
+			}
+			result.tags.oneOf = strings.Split(fieldTagValue, separatorList)
+		case tagValidate:
+			if fieldTagValue == "" {
+				return errors.New(fmt.Sprintf("Empty value of tag %s", tagValidate))
+			}
+			result.tags.validators = strings.Split(fieldTagValue, separatorList)
+		case tagRequiredIf:
+			conditions, err := parseConditionsTag(fieldTagName, fieldTagValue)
+			if err != nil {
+				return err
+			}
+			result.tags.requiredIf = conditions
+		case tagDeprecated:
+			result.tags.deprecated = fieldTagValue
+			result.tags.isDeprecated = true
+		case tagExample:
+			result.tags.example = fieldTagValue
+		case tagLongDesc:
+			result.tags.longDescription = fieldTagValue
+		case tagHidden:
+			hidden, err := parseBoolTag(fieldTagName, fieldTagValue)
+			if err != nil {
+				return err
+			}
+			result.tags.hidden = hidden
+		case tagShort:
+			if len([]rune(fieldTagValue)) != 1 {
+				return errors.New(fmt.Sprintf("Wrong value %s of tag %s. Should be single letter", fieldTagValue, tagShort))
+			}
+			result.tags.short = fieldTagValue
+		case tagAlias:
+			if fieldTagValue == "" {
+				return errors.New(fmt.Sprintf("Empty value of tag %s", tagAlias))
+			}
+			result.tags.aliases = strings.Split(fieldTagValue, separatorList)
+		case tagCount:
+			count, err := parseBoolTag(fieldTagName, fieldTagValue)
+			if err != nil {
+				return err
+			}
+			if count && !isInteger(field.Type.Kind()) {
+				return errors.New(fmt.Sprintf("Tag %s is supported just for integer fields", tagCount))
+			}
+			result.tags.count = count
+		case tagEnv, tagEnvName:
+			if fieldTagValue == "" {
+				return errors.New(fmt.Sprintf("Empty value of tag %s", fieldTagName))
+			}
+			result.tags.env = fieldTagValue
+			if strings.HasPrefix(fieldTagValue, envNoPrefixMark) {
+				result.tags.env = strings.TrimPrefix(fieldTagValue, envNoPrefixMark)
+				result.tags.noPrefix = true
+			}
+		case tagNoPrefix:
+			noPrefix, err := parseBoolTag(fieldTagName, fieldTagValue)
+			if err != nil {
+				return err
+			}
+			result.tags.noPrefix = noPrefix
+		case tagCliName:
+			if fieldTagValue == "" {
+				return errors.New(fmt.Sprintf("Empty value of tag %s", tagCliName))
+			}
+			result.tags.cli = fieldTagValue
+		case tagCfgName:
+			if fieldTagValue == "" {
+				return errors.New(fmt.Sprintf("Empty value of tag %s", tagCfgName))
+			}
+			result.tags.cfg = fieldTagValue
+		case tagEnvCase:
+			envCase, err := parseEnvCaseTag(fieldTagValue)
+			if err != nil {
+				return err
+			}
+			result.tags.envCase = envCase
+		}
+	}
+	if result.tags.name == "" {
+		result.tags.name = jsonName(field)
+	}
+	if result.tags.hasMin {
+		limit, err := parseLimitTag(field.Type, result.tags.unit, tagMin, minValue)
+		if err != nil {
+			return err
+		}
+		result.tags.min = limit
+	}
+	if result.tags.hasMax {
+		limit, err := parseLimitTag(field.Type, result.tags.unit, tagMax, maxValue)
+		if err != nil {
+			return err
 		}
+		result.tags.max = limit
 	}
 	if parent != nil {
 		result.name = fmt.Sprintf("%s%s%s", parent.name, separatorNested, result.name)
 
 		if parent.tags.name != "" {
+			isLeaf := !isNestedStruct(field.Type) && !isNestedStructPointer(field.Type) || result.tags.encoding != ""
+			if result.tags.name == "" && isLeaf { // Ex.: ServerPort in db struct gives db.server_port
+				result.tags.name = autoName(field.Name)
+			}
+			for i, alias := range result.tags.aliases { // Aliases are renamed keys, so they are nested like name
+				result.tags.aliases[i] = fmt.Sprintf("%s%s%s", parent.tags.name, p.nestedSeparator(), alias)
+			}
 			if result.tags.name != "" {
-				result.tags.name = fmt.Sprintf("%s%s%s", parent.tags.name, separatorNested, result.tags.name)
+				result.tags.name = fmt.Sprintf("%s%s%s", parent.tags.name, p.nestedSeparator(), result.tags.name)
 			} else {
 				result.tags.name = parent.tags.name
 			}
@@ -297,8 +755,12 @@ func (p *Parser) newStructField(field reflect.StructField, parent *structField)
 		}
 	}
 
-	if field.Type.Kind() == reflect.Struct {
-		s := reflect.New(field.Type).Elem()
+	structType := field.Type
+	if isNestedStructPointer(structType) {
+		structType = structType.Elem()
+	}
+	if isNestedStruct(structType) && result.tags.encoding == "" {
+		s := reflect.New(structType).Elem()
 		for i := 0; i < s.NumField(); i++ {
 			err := p.newStructField(s.Type().Field(i), result)
 			if err != nil {
@@ -309,33 +771,205 @@ func (p *Parser) newStructField(field reflect.StructField, parent *structField)
 		return nil
 	}
 
+	if result.tags.name == "" { // Name is derived from path of field. Ex.: DB.MaxConns gives db_max_conns
+		result.tags.name = autoName(p.promotedPath(result.name))
+	}
+
 	p.fields[result.name] = result
 	return nil
 }
 
+// Return path of struct field, like name of structField. Ex.: DB.Host
+func fieldPath(field reflect.StructField, parent *structField) string {
+	if parent == nil {
+		return field.Name
+	}
+
+	return fmt.Sprintf("%s%s%s", parent.name, separatorNested, field.Name)
+}
+
+// Set separator of nested config names, used in cli flags and keys of flattened config file (ex.: "__" or "/"),
+// if dots clash with conventions of some source. Default is ".". Keys of external sources should still use "."
+func WithNestedSeparator(sep string) Option {
+	return func(p *Parser) {
+		p.nestedSep = sep
+	}
+}
+
+// Return separator of nested config names
+func (p *Parser) nestedSeparator() string {
+	if p.nestedSep == "" {
+		return separatorNested
+	}
+
+	return p.nestedSep
+}
+
+// Bind just keys of config file object at section path (nested names are joined by nested separator,
+// ex.: "services.api"), so few services can share one config file. Other keys are ignored, and missing section
+// gives empty config
+func WithConfigSection(section string) Option {
+	return func(p *Parser) {
+		p.cfgSection = section
+	}
+}
+
+// Return object of config file section set by WithConfigSection. Return nil if there is no such section
+func (p *Parser) selectSection(content map[string]interface{}) (map[string]interface{}, error) {
+	if p.cfgSection == "" {
+		return content, nil
+	}
+
+	for _, key := range strings.Split(p.cfgSection, p.nestedSeparator()) {
+		value, ok := content[key]
+		if !ok {
+			return nil, nil
+		}
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			return nil, errors.New(fmt.Sprintf("Config section %s should be an object", p.cfgSection))
+		}
+		content = object
+	}
+
+	return content, nil
+}
+
+// Parse fields that have json tag but don't have config tag too. Their names are taken from json tag
+func WithJSONTags() Option {
+	return func(p *Parser) {
+		p.jsonTags = true
+	}
+}
+
+// Make NewParser fail if exported field has no config tag (or json tag with WithJSONTags), so typos like
+// `confg:"..."` are not silently skipped. Fields that shouldn't be parsed need explicit `config:"-"`
+func WithStrictStruct() Option {
+	return func(p *Parser) {
+		p.strictStruct = true
+	}
+}
+
+// Return name of field from its json tag. Return empty string if there is no name
+func jsonName(field reflect.StructField) string {
+	value := field.Tag.Get(jsonTag)
+	if value == tagIgnore {
+		return ""
+	}
+	name, _, _ := strings.Cut(value, ",")
+
+	return name
+}
+
+// Check if struct type should be parsed field by field. Structs with own parsing rules are filled as single values
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != ipNetType && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// Check if type is pointer to struct that should be parsed field by field. Such struct is allocated just if some of
+// its fields got value from some source (not default), so optional sub-configs stay nil
+func isNestedStructPointer(t reflect.Type) bool {
+	return t.Kind() == reflect.Pointer && t != regexpType && isNestedStruct(t.Elem())
+}
+
+// Return field as encoding.TextUnmarshaler if field or pointer to it implements it.
+// Nil pointer fields are set to new value
+func textUnmarshaler(field reflect.Value) (encoding.TextUnmarshaler, bool) {
+	if field.Kind() == reflect.Pointer && field.Type().Implements(textUnmarshalerType) {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return field.Interface().(encoding.TextUnmarshaler), true
+	}
+
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler), true
+	}
+
+	return nil, false
+}
+
+// Parse value of boolean tag. Tag without value (ex.: `secret`) means true
+func parseBoolTag(name, value string) (bool, error) {
+	if value == "" {
+		return true, nil
+	}
+
+	value = strings.ToLower(value)
+	for b, words := range boolValues {
+		for _, word := range words {
+			if value == word {
+				return b, nil
+			}
+		}
+	}
+
+	return false, errors.New(fmt.Sprintf("Wrong value %s of tag %s. Should be boolean", value, name))
+}
+
 // Parse arguments from command line
-func (p *Parser) parseCli(args []string) {
+func (p *Parser) parseCli(args []string) error {
 	p.parsedCli = make(map[string]string)
+	p.args = []string{}
+	args, err := expandResponseFiles(args)
+	if err != nil {
+		return err
+	}
+
+	shorts := p.shortNames()
 	pendingName := ""
-	for _, arg := range args {
-		if '-' != arg[0] {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if argsTerminator == arg { // Everything after terminator is positional, even if it starts with "-"
+			p.args = append(p.args, args[i+1:]...)
+			break
+		}
+
+		if !p.isFlag(arg) {
 			if "" != pendingName {
 				p.parsedCli[pendingName] = arg
 				pendingName = ""
+			} else if i > 0 { // First argument is program name
+				p.args = append(p.args, arg)
 			}
 			continue
 		}
 
-		if '-' == arg[0] && "" != pendingName {
+		if "" != pendingName {
 			p.parsedCli[pendingName] = ""
 			pendingName = ""
 		}
 
 		tmp := strings.Split(arg, "=")
 		name := strings.TrimLeft(tmp[0], "-")
+		if longName, ok := shorts[name]; ok && !strings.HasPrefix(tmp[0], "--") {
+			name = longName
+		}
+
+		if negated, ok := p.negatedBoolName(name); ok && len(tmp) == 1 {
+			p.parsedCli[negated] = "false"
+			continue
+		}
+
+		if counted, times, ok := p.countedName(tmp[0]); ok && len(tmp) == 1 {
+			previous, _ := strconv.Atoi(p.parsedCli[counted])
+			p.parsedCli[counted] = strconv.Itoa(previous + times)
+			continue
+		}
 
 		if len(tmp) == 1 {
-			pendingName = name
+			takesValue, known := p.takesValue(name)
+			switch {
+			case !known: // Type is unknown, so next argument is value only if it is not flag
+				pendingName = name
+			case !takesValue: // Bool flag without value means true
+				p.parsedCli[name] = "true"
+			case i+1 < len(args):
+				p.parsedCli[name] = args[i+1]
+				i++
+			default:
+				p.parsedCli[name] = ""
+			}
 			continue
 		}
 
@@ -345,6 +979,8 @@ func (p *Parser) parseCli(args []string) {
 	if "" != pendingName {
 		p.parsedCli[pendingName] = ""
 	}
+
+	return nil
 }
 
 // Read and parse config file
@@ -355,6 +991,15 @@ func (p *Parser) parseCfg(path string) error {
 		return nil
 	}
 
+	if isURL(path) {
+		fileContent, ext, err := p.fetchCfg(path)
+		if err != nil {
+			return err
+		}
+
+		return p.decodeCfg(fileContent, ext)
+	}
+
 	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
 		return errors.New("Cannot find config file")
 	} else if err != nil {
@@ -366,11 +1011,18 @@ func (p *Parser) parseCfg(path string) error {
 		return err
 	}
 
-	ext := filepath.Ext(path)
+	return p.decodeCfg(fileContent, filepath.Ext(path))
+}
 
+// Parse config content according to its format (file extension)
+func (p *Parser) decodeCfg(fileContent []byte, ext string) error {
 	if ".json" == ext {
 		tmp := make(map[string]interface{})
-		err = json.Unmarshal(fileContent, &tmp)
+		err := json.Unmarshal(fileContent, &tmp)
+		if err != nil {
+			return err
+		}
+		tmp, err = p.selectSection(tmp)
 		if err != nil {
 			return err
 		}
@@ -387,48 +1039,382 @@ func (p *Parser) parseCfg(path string) error {
 func (p *Parser) saveToParsed(tmp map[string]interface{}, prefix string) {
 	for k, v := range tmp {
 		if prefix != "" {
-			k = fmt.Sprintf("%s%s%s", prefix, separatorNested, k)
+			k = fmt.Sprintf("%s%s%s", prefix, p.nestedSeparator(), k)
+		}
+		if _, isString := v.(string); !isString && p.isJSONEncoded(k) { // Json encoded field can be set with json value itself
+			content, _ := json.Marshal(v)
+			p.parsedCfg[k] = string(content)
+			continue
 		}
 		switch c := v.(type) {
 		case map[string]interface{}:
+			if field := p.fieldByCfgName(k); field != nil && p.isMapField(field) {
+				keys := maps.Keys(c)
+				sort.Strings(keys)
+				items := make([]string, len(keys))
+				for i, key := range keys {
+					items[i] = fmt.Sprintf("%s%s%s", key, separatorPair, cfgString(c[key]))
+				}
+				p.parsedCfg[k] = strings.Join(items, field.tags.listSeparator())
+				continue
+			}
 			p.saveToParsed(c, k)
+		case []interface{}:
+			if hasObjects(c) || strings.Contains(k, indexOpen) { // Arrays of objects and arrays inside them are flattened. Ex.: servers[0].host
+				for i, item := range c {
+					p.saveToParsed(map[string]interface{}{indexedKey(k, i): item}, "")
+				}
+				continue
+			}
+			items := make([]string, len(c))
+			for i, item := range c {
+				items[i] = cfgString(item)
+			}
+			p.parsedCfg[k] = strings.Join(items, p.listSeparator(k))
 		default:
-			p.parsedCfg[k] = fmt.Sprint(v)
+			p.parsedCfg[k] = cfgString(v)
+		}
+	}
+}
+
+// Format json value of config file as raw value. Numbers are formatted without exponent, so big integers
+// like 1000000 can be parsed into integer fields
+func cfgString(value interface{}) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// Return separator of list items for config file key. Default separator is used for unknown keys
+func (p *Parser) listSeparator(name string) string {
+	if field := p.fieldByCfgName(name); field != nil {
+		return field.tags.listSeparator()
+	}
+
+	return separatorList
+}
+
+// Find field by config file key (value of cfgname tag or config name) or alias. Return nil if there is no such field
+func (p *Parser) fieldByCfgName(name string) *structField {
+	for _, field := range p.fields {
+		if p.sameKey(field.tags.cfgName(), name) {
+			return field
+		}
+		for _, alias := range field.tags.aliases {
+			if p.sameKey(alias, name) {
+				return field
+			}
+		}
+	}
+
+	return nil
+}
+
+// Find field by config name. Return nil if there is no such field
+func (p *Parser) fieldByConfigName(name string) *structField {
+	for _, field := range p.fields {
+		if field.tags.name == name {
+			return field
 		}
 	}
+
+	return nil
+}
+
+// Check if field with config file key has json encoding
+func (p *Parser) isJSONEncoded(name string) bool {
+	field := p.fieldByCfgName(name)
+	return field != nil && field.tags.encoding == encodingJSON
+}
+
+// Check if struct field has map type
+func (p *Parser) isMapField(field *structField) bool {
+	t := p.fieldType(field)
+	return t != nil && t.Kind() == reflect.Map
+}
+
+// Return type of struct field. Return nil if it can't be found
+func (p *Parser) fieldType(field *structField) reflect.Type {
+	if p.in == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(p.in).Elem()
+	for _, name := range strings.Split(field.name, separatorNested) {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		f, ok := t.FieldByName(name)
+		if !ok {
+			return nil
+		}
+		t = f.Type
+	}
+
+	return t
+}
+
+// Return path of field without embedded structs, whose fields are promoted. Ex.: DB.Options.Port gives DB.Port
+func (p *Parser) promotedPath(path string) string {
+	result := []string{}
+	t := reflect.TypeOf(p.in).Elem()
+	for _, name := range strings.Split(path, separatorNested) {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		f, ok := t.FieldByName(name)
+		if !ok {
+			return path
+		}
+		if !f.Anonymous {
+			result = append(result, name)
+		}
+		t = f.Type
+	}
+
+	return strings.Join(result, separatorNested)
 }
 
 // Look for specific config in allowed (for this field) places
 func (p *Parser) getConfig(name string, mode int) (string, bool) {
+	value, _, _, find := p.lookupConfig(name, nil, mode)
+	return value, find
+}
+
+// Look for specific config in allowed (for this field) places. Return also name of source where value was found,
+// and key of value in that source (flag, env variable or config file key). Sources keep their priority
+// (env < cfg < cli). Inside of one source name has priority over aliases, and aliases are tried in order they are listed
+func (p *Parser) lookupConfig(name string, aliases []string, mode int) (string, string, string, bool) {
 	var value = ""
+	var source = ""
+	var key = ""
 	var find = false
+	names := append([]string{name}, aliases...)
+	cliNames, cfgNames := names, names
+	field := p.fieldByConfigName(name)
+	if field != nil {
+		cliNames = append([]string{field.tags.cliName()}, aliases...)
+		cfgNames = append([]string{field.tags.cfgName()}, aliases...)
+	}
 
 	if 0 == mode || mode&modeEnv > 0 {
-		if tmpValue, ok := os.LookupEnv(strings.ToUpper(fmt.Sprintf("%s%s", p.envPrefix, name))); ok {
+		keys := p.envKeysWithPrefixes(names, p.allEnvPrefixes(), p.envCase)
+		if field != nil {
+			keys = p.envKeys(field.tags)
+		}
+		if tmpValue, foundKey, ok := lookupNames(keys, p.lookupEnvKey); ok {
 			value = tmpValue
+			source = sourceEnv
+			key = foundKey
 			find = true
 		}
 	}
 
 	if 0 == mode || mode&modeCfg > 0 {
-		if tmpValue, ok := p.parsedCfg[name]; ok {
+		if tmpValue, foundName, ok := lookupNames(cfgNames, p.lookupCfg); ok {
 			value = tmpValue
+			source = sourceCfg
+			key = p.keyOf(p.parsedCfg, foundName)
+			if origin, ok := p.cfgOrigins[key]; ok {
+				source = origin
+			}
 			find = true
 		}
 	}
 
 	if 0 == mode || mode&modeCli > 0 {
-		if tmpValue, ok := p.parsedCli[name]; ok {
+		if tmpValue, foundName, ok := lookupNames(cliNames, p.lookupCli); ok {
 			value = tmpValue
+			source = sourceCli
+			key = "--" + p.keyOf(p.parsedCli, foundName)
 			find = true
 		}
 	}
 
-	return value, find
+	return value, source, key, find
+}
+
+// Return value of first name found with lookup function, and that name
+func lookupNames(names []string, lookup func(name string) (string, bool)) (string, string, bool) {
+	for _, name := range names {
+		if value, ok := lookup(name); ok {
+			return value, name, true
+		}
+	}
+
+	return "", "", false
+}
+
+// Look for config in parsed config file and external sources values
+func (p *Parser) lookupCfg(name string) (string, bool) {
+	value, ok := p.parsedCfg[p.keyOf(p.parsedCfg, name)]
+	return value, ok
+}
+
+// Look for array items in flattened config file keys (ex.: hosts[0], hosts[1] or servers[0].host, servers[1].host),
+// if field is slice. Items are taken in order of indexes. Items of slice are joined by separatorEnvItems,
+// and items of slice of structs are encoded as json array of objects. Return value, its source and true if found
+func (p *Parser) lookupCfgItems(field *structField) (string, string, bool) {
+	if field.tags.mode != 0 && field.tags.mode&modeCfg == 0 || field.tags.encoding != "" {
+		return "", "", false
+	}
+	t := p.fieldType(field)
+	if t == nil || t.Kind() != reflect.Slice {
+		return "", "", false
+	}
+	isStructs := isNestedStruct(t.Elem())
+
+	for _, name := range append([]string{field.tags.cfgName()}, field.tags.aliases...) {
+		items := make(map[int]map[string]interface{}) // Keys - indexes
+		source := sourceCfg
+		for key, value := range p.parsedCfg {
+			base, index, rest, ok := splitIndexedKey(key)
+			if !ok || !p.sameKey(base, name) {
+				continue
+			}
+			if isStructs != strings.HasPrefix(rest, p.nestedSeparator()) || !isStructs && rest != "" {
+				continue
+			}
+			if items[index] == nil {
+				items[index] = make(map[string]interface{})
+			}
+			setNested(items[index], strings.Split(strings.TrimPrefix(rest, p.nestedSeparator()), p.nestedSeparator()), value)
+			if origin, ok := p.cfgOrigins[key]; ok {
+				source = origin
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		indexes := maps.Keys(items)
+		sort.Ints(indexes)
+		if isStructs {
+			objects := make([]map[string]interface{}, len(indexes))
+			for i, index := range indexes {
+				objects[i] = items[index]
+			}
+			content, _ := json.Marshal(objects)
+			return string(content), source, true
+		}
+		values := make([]string, len(indexes))
+		for i, index := range indexes {
+			values[i] = fmt.Sprint(items[index][""])
+		}
+		return strings.Join(values, separatorEnvItems), source, true
+	}
+
+	return "", "", false
+}
+
+// Format flattened config file key of array item. Ex.: servers[0]
+func indexedKey(name string, index int) string {
+	return fmt.Sprintf("%s%s%d%s", name, indexOpen, index, indexClose)
+}
+
+// Split flattened config file key of array item into name of array, index of item and rest of key.
+// Ex.: servers[0].host gives servers, 0 and .host
+func splitIndexedKey(key string) (string, int, string, bool) {
+	name, rest, ok := strings.Cut(key, indexOpen)
+	if !ok || name == "" {
+		return "", 0, "", false
+	}
+	number, rest, ok := strings.Cut(rest, indexClose)
+	if !ok {
+		return "", 0, "", false
+	}
+	index, err := strconv.Atoi(number)
+	if err != nil || index < 0 {
+		return "", 0, "", false
+	}
+
+	return name, index, rest, true
+}
+
+// Put value into nested objects by path of keys. Ex.: tls, cert gives {"tls": {"cert": value}}
+func setNested(object map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := object[key].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			object[key] = child
+		}
+		object = child
+	}
+	object[path[len(path)-1]] = value
+}
+
+// Look for config in parsed command-line arguments
+func (p *Parser) lookupCli(name string) (string, bool) {
+	value, ok := p.parsedCli[p.keyOf(p.parsedCli, name)]
+	return value, ok
 }
 
 // Convert founded value to required type, and put it into struct field
-func (p *Parser) writeValueToField(field reflect.Value, value string) error {
+func (p *Parser) writeValueToField(field reflect.Value, value string, tags structFieldTags) error {
+	if tags.encoding != "" {
+		return encodings[tags.encoding](field, value, tags)
+	}
+
+	if tags.unit != "" && field.Kind() != reflect.Slice && field.Kind() != reflect.Map {
+		return writeUnitToField(field, value, tags)
+	}
+
+	if field.Type() == durationType {
+		convValue, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(convValue))
+
+		return nil
+	}
+
+	switch field.Type() {
+	case ipType, ipNetType, reflect.PointerTo(ipNetType):
+		if value == "" { // Empty address, ex.: key of sample config file
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+	}
+
+	switch field.Type() {
+	case ipType:
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return errors.New(fmt.Sprintf("Wrong IP address %s of %s", value, tags.name))
+		}
+		field.Set(reflect.ValueOf(ip))
+
+		return nil
+	case ipNetType, reflect.PointerTo(ipNetType):
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Wrong CIDR range %s of %s", value, tags.name))
+		}
+		if field.Kind() == reflect.Pointer {
+			field.Set(reflect.ValueOf(ipNet))
+		} else {
+			field.Set(reflect.ValueOf(*ipNet))
+		}
+
+		return nil
+	case regexpType:
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Wrong regular expression of %s: %s", tags.name, err))
+		}
+		field.Set(reflect.ValueOf(re))
+
+		return nil
+	}
+
+	if unmarshaler, ok := textUnmarshaler(field); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+
 	switch field.Type().Kind() {
 	case reflect.Bool:
 		value = strings.ToLower(value)
@@ -530,9 +1516,9 @@ func (p *Parser) writeValueToField(field reflect.Value, value string) error {
 	case reflect.Chan:
 		return errors.New("Chan are not supported yet")
 	case reflect.Map:
-		return errors.New("Map are not supported yet")
+		return p.writeMapToField(field, value, tags)
 	case reflect.Slice:
-		return errors.New("Slice are not supported yet")
+		return p.writeSliceToField(field, value, tags)
 	case reflect.String:
 		field.SetString(value)
 	case reflect.Struct:
@@ -543,3 +1529,153 @@ func (p *Parser) writeValueToField(field reflect.Value, value string) error {
 
 	return nil
 }
+
+// Split value into items and put them into slice field. Each item is converted as separate value
+func (p *Parser) writeSliceToField(field reflect.Value, value string, tags structFieldTags) error {
+	if isNestedStruct(field.Type().Elem()) {
+		return p.writeStructSliceToField(field, value, tags)
+	}
+
+	items := tags.splitList(value)
+	slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+	for i, item := range items {
+		err := p.writeValueToField(slice.Index(i), strings.TrimSpace(item), tags)
+		if err != nil {
+			return err
+		}
+	}
+	field.Set(slice)
+
+	return nil
+}
+
+// Decode value as json array of objects and put them into slice of structs field.
+// Each object is parsed with fields tags of struct, like separate config file
+func (p *Parser) writeStructSliceToField(field reflect.Value, value string, tags structFieldTags) error {
+	items := []map[string]interface{}{}
+	if value != "" {
+		err := json.Unmarshal([]byte(value), &items)
+		if err != nil {
+			return fmt.Errorf("Wrong value of %s. Should be json array of objects: %w", tags.name, err)
+		}
+	}
+
+	slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+	for i, item := range items {
+		elem := reflect.New(field.Type().Elem())
+		itemParser, err := NewParser(elem.Interface())
+		if err != nil {
+			return err
+		}
+		for _, itemField := range itemParser.fields {
+			itemField.tags.mode = modeCfg
+		}
+		itemParser.parsedCfg = make(map[string]string)
+		itemParser.saveToParsed(item, "")
+
+		err = itemParser.fillStructWithValues(elem.Interface(), "")
+		if err != nil {
+			return fmt.Errorf("Wrong item %d of %s: %w", i, tags.name, err)
+		}
+		slice.Index(i).Set(elem.Elem())
+	}
+	field.Set(slice)
+
+	return nil
+}
+
+// Check if json array contains objects
+func hasObjects(items []interface{}) bool {
+	for _, item := range items {
+		if _, ok := item.(map[string]interface{}); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Split value into key=value pairs and put them into map field. Keys and values are converted as separate values
+func (p *Parser) writeMapToField(field reflect.Value, value string, tags structFieldTags) error {
+	result := reflect.MakeMap(field.Type())
+	if value != "" {
+		for _, item := range tags.splitList(value) {
+			pair := strings.SplitN(item, separatorPair, 2)
+			if len(pair) != 2 {
+				return errors.New(fmt.Sprintf("Wrong map item %s. Should be key%svalue", item, separatorPair))
+			}
+
+			key := reflect.New(field.Type().Key()).Elem()
+			err := p.writeValueToField(key, strings.TrimSpace(pair[0]), tags)
+			if err != nil {
+				return err
+			}
+			elem := reflect.New(field.Type().Elem()).Elem()
+			err = p.writeValueToField(elem, strings.TrimSpace(pair[1]), tags)
+			if err != nil {
+				return err
+			}
+			result.SetMapIndex(key, elem)
+		}
+	}
+	field.Set(result)
+
+	return nil
+}
+
+// Return tags of field with separator of list items, applied to value from source
+func (p *Parser) sourceTags(tags structFieldTags, source string) structFieldTags {
+	if source == sourceEnv && tags.separator == "" {
+		tags.separator = p.envListSeparator
+	}
+
+	return tags
+}
+
+// Return separator of list items in field value
+func (t structFieldTags) listSeparator() string {
+	if t.separator == "" {
+		return separatorList
+	}
+
+	return t.separator
+}
+
+// Split value into list items. Separator of spaces splits by any whitespace, so repeated spaces don't give empty items
+func (t structFieldTags) splitList(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	if strings.TrimSpace(t.listSeparator()) == "" {
+		return strings.Fields(value)
+	}
+
+	return strings.Split(value, t.listSeparator())
+}
+
+// Return name used for command-line flag of field: value of cliname tag or config name
+func (t structFieldTags) cliName() string {
+	if t.cli == "" {
+		return t.name
+	}
+
+	return t.cli
+}
+
+// Return key used in config file of field: value of cfgname tag or config name
+func (t structFieldTags) cfgName() string {
+	if t.cfg == "" {
+		return t.name
+	}
+
+	return t.cfg
+}
+
+// Return name used for env variable of field: value of env tag or config name
+func (t structFieldTags) envName() string {
+	if t.env == "" {
+		return t.name
+	}
+
+	return t.env
+}
@@ -3,10 +3,15 @@ package config
 import (
 	"fmt"
 	"math"
+	"net"
+	"net/netip"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"strconv"
 	"testing"
+	"time"
 	"unsafe"
 )
 
@@ -75,11 +80,12 @@ func TestNewParser(t *testing.T) {
 
 func TestParser_Help(t *testing.T) {
 	type fields struct {
-		in        interface{}
-		fields    map[string]*structField
-		envPrefix string
-		parsedCfg map[string]string
-		parsedCli map[string]string
+		in              interface{}
+		fields          map[string]*structField
+		envPrefix       string
+		parsedCfg       map[string]string
+		parsedCli       map[string]string
+		helpSourceNames bool
 	}
 	type args struct {
 		prefix string
@@ -147,6 +153,15 @@ func TestParser_Help(t *testing.T) {
 							hasDescription: true,
 						},
 					},
+					"hidden_field": {
+						name: "hidden_field",
+						tags: structFieldTags{
+							name:           "hidden_with_long_name",
+							description:    "Internal option",
+							hasDescription: true,
+							hidden:         true,
+						},
+					},
 				},
 			},
 			want: `--afffffff     Some more description (cli, cfg only)
@@ -185,17 +200,66 @@ func TestParser_Help(t *testing.T) {
 			args: args{prefix: "        "},
 			want: `        --f[=1]  Some description
         --ff[=2] Some description two
+`,
+		},
+		{
+			name: "env prefix",
+			fields: fields{
+				fields: map[string]*structField{
+					"first_field": {
+						name: "DB.Host",
+						tags: structFieldTags{
+							name:           "db.host",
+							mode:           modeEnv,
+							description:    "Database host",
+							hasDescription: true,
+						},
+					},
+				},
+				envPrefix:       "APP_",
+				helpSourceNames: true,
+			},
+			want: `--db.host Database host (env APP_DB_HOST only)
+`,
+		},
+		{
+			name: "source names",
+			fields: fields{
+				fields: map[string]*structField{
+					"first_field": {
+						name: "Port",
+						tags: structFieldTags{
+							name:           "port",
+							description:    "Port to listen",
+							hasDescription: true,
+						},
+					},
+					"second_field": {
+						name: "DB.User",
+						tags: structFieldTags{
+							name:           "db.user",
+							mode:           modeCli | modeCfg,
+							description:    "Database user",
+							hasDescription: true,
+						},
+					},
+				},
+				helpSourceNames: true,
+			},
+			want: `--db.user Database user (cli, cfg db.user)
+--port    Port to listen (cli, cfg port, env PORT)
 `,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			p := &Parser{
-				in:        tt.fields.in,
-				fields:    tt.fields.fields,
-				envPrefix: tt.fields.envPrefix,
-				parsedCfg: tt.fields.parsedCfg,
-				parsedCli: tt.fields.parsedCli,
+				in:              tt.fields.in,
+				fields:          tt.fields.fields,
+				envPrefix:       tt.fields.envPrefix,
+				parsedCfg:       tt.fields.parsedCfg,
+				parsedCli:       tt.fields.parsedCli,
+				helpSourceNames: tt.fields.helpSourceNames,
 			}
 			if got := p.Help(tt.args.prefix); got != tt.want {
 				t.Errorf("Parser.Help() = \n%v\n, want \n%v\n", got, tt.want)
@@ -455,6 +519,12 @@ func TestParser_newStructField(t *testing.T) {
 		NestedErr struct {
 			Err string `config:"name:nested.err;mode:cfg"`
 		} `config:"mode:cli"`
+		Hosts   []string  `config:"name:hosts;sep:|"`
+		Network net.IPNet `config:"name:network"`
+		Ignored struct {
+			Int int `config:"name:int"`
+		} `config:"-"`
+		Since time.Time `config:"name:since"`
 	}
 	type fields struct {
 		in        interface{}
@@ -520,6 +590,34 @@ func TestParser_newStructField(t *testing.T) {
 			want:    map[string]*structField{},
 			wantErr: true,
 		},
+		{
+			name:    "separator",
+			fields:  fields{in: &str{}, fields: make(map[string]*structField)},
+			args:    args{field: reflect.ValueOf(&str{}).Elem().Type().Field(6)},
+			want:    map[string]*structField{"Hosts": {name: "Hosts", tags: structFieldTags{name: "hosts", separator: "|"}}},
+			wantErr: false,
+		},
+		{
+			name:    "ignored",
+			fields:  fields{in: &str{}, fields: make(map[string]*structField)},
+			args:    args{field: reflect.ValueOf(&str{}).Elem().Type().Field(8)},
+			want:    map[string]*structField{},
+			wantErr: false,
+		},
+		{
+			name:    "ipnet",
+			fields:  fields{in: &str{}, fields: make(map[string]*structField)},
+			args:    args{field: reflect.ValueOf(&str{}).Elem().Type().Field(7)},
+			want:    map[string]*structField{"Network": {name: "Network", tags: structFieldTags{name: "network"}}},
+			wantErr: false,
+		},
+		{
+			name:    "text unmarshaler",
+			fields:  fields{in: &str{}, fields: make(map[string]*structField)},
+			args:    args{field: reflect.ValueOf(&str{}).Elem().Type().Field(9)},
+			want:    map[string]*structField{"Since": {name: "Since", tags: structFieldTags{name: "since"}}},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -544,21 +642,29 @@ func TestParser_newStructField(t *testing.T) {
 
 func TestParser_parseCli(t *testing.T) {
 	tests := []struct {
-		name string
-		args []string
-		want map[string]string
+		name     string
+		args     []string
+		want     map[string]string
+		wantArgs []string
 	}{
 		{name: "empty", args: []string{}, want: map[string]string{}},
-		{name: "cmd", args: []string{"/buffbot"}, want: map[string]string{}},
-		{name: "subcmd", args: []string{"/buffbot", "test"}, want: map[string]string{}},
+		{name: "cmd", args: []string{"/buffbot"}, want: map[string]string{}, wantArgs: []string{}},
+		{name: "subcmd", args: []string{"/buffbot", "test"}, want: map[string]string{}, wantArgs: []string{"test"}},
 		{name: "single bool", args: []string{"/buffbot", "test", "-t"}, want: map[string]string{"t": ""}},
 		{name: "single param", args: []string{"/buffbot", "test", "-t", "t"}, want: map[string]string{"t": "t"}},
 		{name: "single few param", args: []string{"/buffbot", "test", "-t", "-p"}, want: map[string]string{"t": "", "p": ""}},
 		{name: "single param equal", args: []string{"/buffbot", "test", "-t=t"}, want: map[string]string{"t": "t"}},
 		{name: "double bool", args: []string{"/buffbot", "test", "--param_bool"}, want: map[string]string{"param_bool": ""}},
 		{name: "double param", args: []string{"/buffbot", "test", "--param_bool=/lorem"}, want: map[string]string{"param_bool": "/lorem"}},
-		{name: "double param extra", args: []string{"/buffbot", "test", "--param_bool=/lorem", "ipsum"}, want: map[string]string{"param_bool": "/lorem"}},
+		{name: "double param extra", args: []string{"/buffbot", "test", "--param_bool=/lorem", "ipsum"}, want: map[string]string{"param_bool": "/lorem"}, wantArgs: []string{"test", "ipsum"}},
 		{name: "double few param", args: []string{"/buffbot", "test", "--param_bool=/lorem", "--p=test", "-m"}, want: map[string]string{"param_bool": "/lorem", "p": "test", "m": ""}},
+		{name: "empty arg", args: []string{"/buffbot", "--p", ""}, want: map[string]string{"p": ""}},
+		{name: "terminator", args: []string{"/buffbot", "--p=test", "--", "-m", "--x=1"}, want: map[string]string{"p": "test"}, wantArgs: []string{"-m", "--x=1"}},
+		{name: "terminator after bool", args: []string{"/buffbot", "-m", "--", "file"}, want: map[string]string{"m": ""}, wantArgs: []string{"file"}},
+		{name: "positional and terminator", args: []string{"/buffbot", "copy", "--p=1", "a.txt", "--", "-b.txt"}, want: map[string]string{"p": "1"}, wantArgs: []string{"copy", "a.txt", "-b.txt"}},
+		{name: "negative value", args: []string{"/buffbot", "--offset", "-5", "-t", "-0.5"}, want: map[string]string{"offset": "-5", "t": "-0.5"}},
+		{name: "negative value equal", args: []string{"/buffbot", "--offset=-5"}, want: map[string]string{"offset": "-5"}},
+		{name: "terminator at end", args: []string{"/buffbot", "--p=test", "--"}, want: map[string]string{"p": "test"}, wantArgs: []string{}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -567,6 +673,9 @@ func TestParser_parseCli(t *testing.T) {
 			if !reflect.DeepEqual(tt.want, p.parsedCli) {
 				t.Errorf("Parser.newStructField() = %v, want %v", p.parsedCli, tt.want)
 			}
+			if tt.wantArgs != nil && !reflect.DeepEqual(tt.wantArgs, p.Args()) {
+				t.Errorf("Parser.Args() = %v, want %v", p.Args(), tt.wantArgs)
+			}
 		})
 	}
 }
@@ -719,6 +828,78 @@ func TestParser_saveToParsed(t *testing.T) {
 				"nested.nested.more": "123",
 			},
 		},
+		{
+			name: "big numbers",
+			fields: fields{
+				parsedCfg: make(map[string]string),
+			},
+			args: args{
+				tmp: map[string]interface{}{
+					"size":  float64(1000000),
+					"ratio": 0.000001,
+					"list":  []interface{}{float64(2e7), float64(1)},
+				},
+			},
+			want: map[string]string{
+				"size":  "1000000",
+				"ratio": "0.000001",
+				"list":  "20000000,1",
+			},
+		},
+		{
+			name: "list",
+			fields: fields{
+				fields:    map[string]*structField{"Ports": {name: "Ports", tags: structFieldTags{name: "nested.ports", separator: "|"}}},
+				parsedCfg: make(map[string]string),
+			},
+			args: args{
+				tmp: map[string]interface{}{
+					"hosts":  []interface{}{"a", "b"},
+					"nested": map[string]interface{}{"ports": []interface{}{80, 443}},
+				},
+			},
+			want: map[string]string{
+				"hosts":        "a,b",
+				"nested.ports": "80|443",
+			},
+		},
+		{
+			name: "list of objects",
+			fields: fields{
+				parsedCfg: make(map[string]string),
+			},
+			args: args{
+				tmp: map[string]interface{}{
+					"upstreams": []interface{}{map[string]interface{}{"host": "a", "port": 80}, map[string]interface{}{"host": "b"}},
+				},
+			},
+			want: map[string]string{
+				"upstreams[0].host": "a",
+				"upstreams[0].port": "80",
+				"upstreams[1].host": "b",
+			},
+		},
+		{
+			name: "map",
+			fields: fields{
+				in: &struct {
+					Nested struct {
+						Labels map[string]string
+					}
+				}{},
+				fields:    map[string]*structField{"Nested.Labels": {name: "Nested.Labels", tags: structFieldTags{name: "nested.labels"}}},
+				parsedCfg: make(map[string]string),
+			},
+			args: args{
+				tmp: map[string]interface{}{
+					"nested": map[string]interface{}{"labels": map[string]interface{}{"env": "prod", "team": "core"}, "other": map[string]interface{}{"a": 1}},
+				},
+			},
+			want: map[string]string{
+				"nested.labels":  "env=prod,team=core",
+				"nested.other.a": "1",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -808,29 +989,51 @@ func TestParser_writeValueToField(t *testing.T) {
 		key   string
 		value string
 
-		VarBool          bool
-		VarInt           int
-		VarInt8          int8
-		VarInt16         int16
-		VarInt32         int32
-		VarInt64         int64
-		VarUint          uint
-		VarUint8         uint8
-		VarUint16        uint16
-		VarUint32        uint32
-		VarUint64        uint64
-		VarUintptr       uintptr
-		VarFloat32       float32
-		VarFloat64       float64
-		VarComplex64     complex64
-		VarComplex128    complex128
-		VarArray         [5]bool
-		VarChan          chan<- bool
-		VarFunc          func()
-		VarInterface     interface{}
-		VarMap           map[int]string
+		VarBool         bool
+		VarInt          int
+		VarInt8         int8
+		VarInt16        int16
+		VarInt32        int32
+		VarInt64        int64
+		VarUint         uint
+		VarUint8        uint8
+		VarUint16       uint16
+		VarUint32       uint32
+		VarUint64       uint64
+		VarUintptr      uintptr
+		VarFloat32      float32
+		VarFloat64      float64
+		VarComplex64    complex64
+		VarComplex128   complex128
+		VarArray        [5]bool
+		VarChan         chan<- bool
+		VarFunc         func()
+		VarInterface    interface{}
+		VarMap          map[int]string
+		VarLimits       map[string]int
+		VarDuration     time.Duration
+		VarDurations    []time.Duration
+		VarIP           net.IP
+		VarIPs          []net.IP
+		VarIPNet        net.IPNet
+		VarIPNetPointer *net.IPNet
+		VarAddr         netip.Addr
+		VarAddrPointer  *netip.Addr
+		VarTime         time.Time
+		VarRegexp       *regexp.Regexp
+		VarRegexps      []*regexp.Regexp
+		VarUpstreams    []struct {
+			Host string `config:"name:host"`
+			Port int    `config:"name:port;default:80"`
+			TLS  struct {
+				Enabled bool `config:"name:enabled"`
+			} `config:"name:tls"`
+		}
 		VarPointer       *bool
 		VarSlice         []byte
+		VarStrings       []string
+		VarInts          []int
+		VarFloats        []float64
 		VarString        string
 		VarStruct        struct{}
 		VarUnsafePointer unsafe.Pointer
@@ -840,6 +1043,7 @@ func TestParser_writeValueToField(t *testing.T) {
 		name    string
 		fields  fields
 		args    args
+		tags    structFieldTags
 		want    func(Test) bool
 		wantErr bool
 	}
@@ -880,9 +1084,41 @@ func TestParser_writeValueToField(t *testing.T) {
 		{name: "chan", fields: fields{}, args: args{key: "VarChan", value: ""}, want: func(t Test) bool { return true }, wantErr: true},
 		{name: "func", fields: fields{}, args: args{key: "VarFunc", value: ""}, want: func(t Test) bool { return true }, wantErr: true},
 		{name: "interface", fields: fields{}, args: args{key: "VarInterface", value: ""}, want: func(t Test) bool { return true }, wantErr: true},
-		{name: "map", fields: fields{}, args: args{key: "VarMap", value: ""}, want: func(t Test) bool { return true }, wantErr: true},
+		{name: "map", fields: fields{}, args: args{key: "VarMap", value: ""}, want: func(t Test) bool { return t.args.VarMap != nil && len(t.args.VarMap) == 0 }, wantErr: false},
+		{name: "map items", fields: fields{}, args: args{key: "VarMap", value: "1=a, 2=b=c"}, want: func(t Test) bool { return reflect.DeepEqual(t.args.VarMap, map[int]string{1: "a", 2: "b=c"}) }, wantErr: false},
+		{name: "map ints", fields: fields{}, args: args{key: "VarLimits", value: "a=1;b=2"}, tags: structFieldTags{separator: ";"}, want: func(t Test) bool { return reflect.DeepEqual(t.args.VarLimits, map[string]int{"a": 1, "b": 2}) }, wantErr: false},
+		{name: "map item err", fields: fields{}, args: args{key: "VarMap", value: "1"}, want: func(t Test) bool { return true }, wantErr: true},
+		{name: "map key err", fields: fields{}, args: args{key: "VarMap", value: "ZZZ=a"}, want: func(t Test) bool { return true }, wantErr: true},
 		{name: "pointer", fields: fields{}, args: args{key: "VarPointer", value: ""}, want: func(t Test) bool { return true }, wantErr: true},
-		{name: "slice", fields: fields{}, args: args{key: "VarSlice", value: ""}, want: func(t Test) bool { return true }, wantErr: true},
+		{name: "slice", fields: fields{}, args: args{key: "VarSlice", value: ""}, want: func(t Test) bool { return t.args.VarSlice != nil && len(t.args.VarSlice) == 0 }, wantErr: false},
+		{name: "slice strings", fields: fields{}, args: args{key: "VarStrings", value: "a, b,c"}, want: func(t Test) bool { return reflect.DeepEqual(t.args.VarStrings, []string{"a", "b", "c"}) }, wantErr: false},
+		{name: "slice ints sep", fields: fields{}, args: args{key: "VarInts", value: "1|2|3"}, tags: structFieldTags{separator: "|"}, want: func(t Test) bool { return reflect.DeepEqual(t.args.VarInts, []int{1, 2, 3}) }, wantErr: false},
+		{name: "slice floats", fields: fields{}, args: args{key: "VarFloats", value: "0.5,1"}, want: func(t Test) bool { return reflect.DeepEqual(t.args.VarFloats, []float64{0.5, 1}) }, wantErr: false},
+		{name: "slice err", fields: fields{}, args: args{key: "VarInts", value: "1,ZZZ"}, want: func(t Test) bool { return true }, wantErr: true},
+		{name: "duration", fields: fields{}, args: args{key: "VarDuration", value: "1m30s"}, want: func(t Test) bool { return t.args.VarDuration == 90*time.Second }, wantErr: false},
+		{name: "duration err", fields: fields{}, args: args{key: "VarDuration", value: "30"}, want: func(t Test) bool { return true }, wantErr: true},
+		{name: "durations", fields: fields{}, args: args{key: "VarDurations", value: "1s,1h"}, want: func(t Test) bool {
+			return reflect.DeepEqual(t.args.VarDurations, []time.Duration{time.Second, time.Hour})
+		}, wantErr: false},
+		{name: "ip", fields: fields{}, args: args{key: "VarIP", value: "10.0.0.1"}, want: func(t Test) bool { return t.args.VarIP.Equal(net.IPv4(10, 0, 0, 1)) }, wantErr: false},
+		{name: "ip err", fields: fields{}, args: args{key: "VarIP", value: "10.0.0"}, want: func(t Test) bool { return true }, wantErr: true},
+		{name: "ips", fields: fields{}, args: args{key: "VarIPs", value: "::1,127.0.0.1"}, want: func(t Test) bool { return len(t.args.VarIPs) == 2 && t.args.VarIPs[0].Equal(net.IPv6loopback) }, wantErr: false},
+		{name: "ipnet", fields: fields{}, args: args{key: "VarIPNet", value: "10.0.0.0/8"}, want: func(t Test) bool { return t.args.VarIPNet.String() == "10.0.0.0/8" }, wantErr: false},
+		{name: "ipnet pointer", fields: fields{}, args: args{key: "VarIPNetPointer", value: "fd00::/8"}, want: func(t Test) bool { return t.args.VarIPNetPointer.String() == "fd00::/8" }, wantErr: false},
+		{name: "ipnet err", fields: fields{}, args: args{key: "VarIPNet", value: "10.0.0.0"}, want: func(t Test) bool { return true }, wantErr: true},
+		{name: "regexp", fields: fields{}, args: args{key: "VarRegexp", value: "^/api/v[0-9]+"}, want: func(t Test) bool { return t.args.VarRegexp.MatchString("/api/v2/users") }, wantErr: false},
+		{name: "regexp err", fields: fields{}, args: args{key: "VarRegexp", value: "(ZZZ"}, want: func(t Test) bool { return true }, wantErr: true},
+		{name: "regexps", fields: fields{}, args: args{key: "VarRegexps", value: "^a,b$"}, want: func(t Test) bool { return len(t.args.VarRegexps) == 2 && t.args.VarRegexps[1].MatchString("ab") }, wantErr: false},
+		{name: "text unmarshaler", fields: fields{}, args: args{key: "VarAddr", value: "10.0.0.1"}, want: func(t Test) bool { return t.args.VarAddr == netip.MustParseAddr("10.0.0.1") }, wantErr: false},
+		{name: "text unmarshaler pointer", fields: fields{}, args: args{key: "VarAddrPointer", value: "::1"}, want: func(t Test) bool { return *t.args.VarAddrPointer == netip.IPv6Loopback() }, wantErr: false},
+		{name: "text unmarshaler struct", fields: fields{}, args: args{key: "VarTime", value: "2022-06-01T10:00:00Z"}, want: func(t Test) bool { return t.args.VarTime.Equal(time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)) }, wantErr: false},
+		{name: "text unmarshaler err", fields: fields{}, args: args{key: "VarAddr", value: "ZZZ"}, want: func(t Test) bool { return true }, wantErr: true},
+		{name: "slice structs", fields: fields{}, args: args{key: "VarUpstreams", value: `[{"host":"a","port":8080,"tls":{"enabled":true}},{"host":"b"}]`}, want: func(t Test) bool {
+			u := t.args.VarUpstreams
+			return len(u) == 2 && u[0].Host == "a" && u[0].Port == 8080 && u[0].TLS.Enabled && u[1].Host == "b" && u[1].Port == 80
+		}, wantErr: false},
+		{name: "slice structs err", fields: fields{}, args: args{key: "VarUpstreams", value: `[{"port":"ZZZ"}]`}, want: func(t Test) bool { return true }, wantErr: true},
+		{name: "slice structs json err", fields: fields{}, args: args{key: "VarUpstreams", value: "a,b"}, want: func(t Test) bool { return true }, wantErr: true},
 		{name: "string", fields: fields{}, args: args{key: "VarString", value: "FDSfsdfasdfsDfe62 sd fsf4t"}, want: func(t Test) bool { return t.args.VarString == "FDSfsdfasdfsDfe62 sd fsf4t" }, wantErr: false},
 		{name: "struct", fields: fields{}, args: args{key: "VarStruct", value: ""}, want: func(t Test) bool { return true }, wantErr: true},
 		{name: "unsafepointer", fields: fields{}, args: args{key: "VarUnsafePointer", value: ""}, want: func(t Test) bool { return true }, wantErr: true},
@@ -896,7 +1132,7 @@ func TestParser_writeValueToField(t *testing.T) {
 				parsedCfg: tt.fields.parsedCfg,
 				parsedCli: tt.fields.parsedCli,
 			}
-			if err := p.writeValueToField(reflect.ValueOf(&tt.args).Elem().FieldByName(tt.args.key), tt.args.value); (err != nil) != tt.wantErr {
+			if err := p.writeValueToField(reflect.ValueOf(&tt.args).Elem().FieldByName(tt.args.key), tt.args.value, tt.tags); (err != nil) != tt.wantErr {
 				t.Errorf("Parser.writeValueToField() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if !tt.want(tt) {
@@ -905,3 +1141,422 @@ func TestParser_writeValueToField(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_Parse_ignore(t *testing.T) {
+	type testStruct struct {
+		Port    int    `config:"name:port;mode:cli"`
+		Ignored string `config:"-"`
+		Runtime struct {
+			Count int `config:"name:count"`
+		} `config:"-"`
+	}
+
+	cfg := testStruct{Ignored: "keep"}
+	cfg.Runtime.Count = 5
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.fields) != 1 {
+		t.Errorf("NewParser() fields = %v, want just port", p.fields)
+	}
+
+	p.parsedCli = map[string]string{"port": "80", "count": "1", "-": "zzz"}
+	if err = p.fillStructWithValues(&cfg, ""); err != nil {
+		t.Fatalf("Parser.fillStructWithValues() error = %v", err)
+	}
+	if cfg.Port != 80 || cfg.Ignored != "keep" || cfg.Runtime.Count != 5 {
+		t.Errorf("Parser.fillStructWithValues() = %+v", cfg)
+	}
+}
+
+func TestParser_Parse_jsonNames(t *testing.T) {
+	type testStruct struct {
+		Host  string `json:"host" config:"mode:cli"`
+		Level string `json:"level" config:"name:log_level"`
+		Port  int    `json:"port,omitempty"`
+		Skip  string `json:"-"`
+		Plain string
+		DB    struct {
+			User string `json:"user"`
+		} `json:"db"`
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want testStruct
+	}{
+		{name: "config tag", want: testStruct{Host: "a", Level: "debug"}},
+		{name: "json tags", opts: []Option{WithJSONTags()}, want: testStruct{Host: "a", Level: "debug", Port: 80, DB: struct {
+			User string `json:"user"`
+		}{User: "root"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg testStruct
+			p, err := NewParser(&cfg, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.parsedCli = map[string]string{"host": "a", "log_level": "debug", "port": "80", "db.user": "root", "-": "x", "Skip": "x", "plain": "x", "Plain": "x"}
+			if err = p.fillStructWithValues(&cfg, ""); err != nil {
+				t.Fatalf("Parser.fillStructWithValues() error = %v", err)
+			}
+			if cfg != tt.want {
+				t.Errorf("Parser.fillStructWithValues() = %+v, want %+v", cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewParser_autoName(t *testing.T) {
+	type testStruct struct {
+		ServerPort int `config:""`
+		DB         struct {
+			MaxConns int    `config:""`
+			Host     string `config:"name:host"`
+			Pool     struct {
+				IdleSize int `config:""`
+			} `config:""`
+		} `config:"name:db"`
+		Cache struct {
+			TTLSeconds int `config:""`
+		} `config:""`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for name, field := range p.fields {
+		got[name] = field.tags.name
+	}
+	want := map[string]string{
+		"ServerPort":       "server_port",
+		"DB.MaxConns":      "db.max_conns",
+		"DB.Host":          "db.host",
+		"DB.Pool.IdleSize": "db.idle_size",
+		"Cache.TTLSeconds": "cache_ttl_seconds",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewParser() names = %v, want %v", got, want)
+	}
+}
+
+func TestWithNestedSeparator(t *testing.T) {
+	type testStruct struct {
+		Config string `config:"name:config;mode:cli"`
+		DB     struct {
+			Host string `config:"name:host;mode:cli"`
+			Port int    `config:"name:port;mode:cfg"`
+			User string `config:"name:user;mode:env"`
+			Name string `config:"name:name;mode:cfg"`
+		} `config:"name:db;mode:cli,cfg,env"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"db":{"port":5432}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Args = []string{"/app/test", "--config=" + path, "--db__host=localhost", "--db.host=zzz"}
+	t.Setenv("DB_USER", "root")
+
+	var cfg testStruct
+	src := &staticSource{name: "vault", values: map[string]string{"db.name": "app"}}
+	p, err := NewParser(&cfg, WithNestedSeparator("__"), WithSource(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("config", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if cfg.DB.Host != "localhost" || cfg.DB.Port != 5432 || cfg.DB.User != "root" || cfg.DB.Name != "app" {
+		t.Errorf("Parser.Parse() = %+v", cfg)
+	}
+	if got := p.Snapshot().Sources["db__name"]; got != "vault" {
+		t.Errorf("Parser.Snapshot() source of db__name = %v, want %v", got, "vault")
+	}
+}
+
+func TestWithConfigSection(t *testing.T) {
+	type testStruct struct {
+		Config string `config:"name:config;mode:cli"`
+		Port   int    `config:"name:port;mode:cfg;default:80"`
+		DB     struct {
+			Host string `config:"name:host"`
+		} `config:"name:db;mode:cfg"`
+	}
+
+	file := `{"port":1,"services":{"api":{"port":8080,"db":{"host":"api-db"}},"worker":{"port":9090}},"flag":true}`
+	tests := []struct {
+		name     string
+		section  string
+		wantPort int
+		wantHost string
+		wantErr  bool
+	}{
+		{name: "no section", section: "", wantPort: 1},
+		{name: "nested section", section: "services.api", wantPort: 8080, wantHost: "api-db"},
+		{name: "other section", section: "services.worker", wantPort: 9090},
+		{name: "missing section", section: "services.admin", wantPort: 80},
+		{name: "not object", section: "flag", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+				t.Fatal(err)
+			}
+			os.Args = []string{"/app/test", "--config=" + path}
+
+			var cfg testStruct
+			p, err := NewParser(&cfg, WithConfigSection(tt.section))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.Parse("config", "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parser.Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.Port != tt.wantPort || cfg.DB.Host != tt.wantHost {
+				t.Errorf("Parser.Parse() = %+v, want port %v and host %v", cfg, tt.wantPort, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_arrayItems(t *testing.T) {
+	type server struct {
+		Host  string   `config:"name:host"`
+		Port  int      `config:"name:port;default:80"`
+		Tags  []string `config:"name:tags;sep:|"`
+		Proxy struct {
+			URL string `config:"name:url"`
+		} `config:"name:proxy"`
+	}
+	type testStruct struct {
+		Config  string   `config:"name:config;mode:cli"`
+		Servers []server `config:"name:servers;mode:cfg,env"`
+		Hosts   []string `config:"name:hosts;mode:cfg"`
+	}
+
+	file := `{"servers":[{"host":"a","port":8080,"tags":["x","y"],"proxy":{"url":"http://proxy"}},{"host":"b"}]}`
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"/app/test", "--config=" + path}
+	t.Setenv("SERVERS", `[{"host":"env"}]`)
+
+	var cfg testStruct
+	src := &staticSource{name: "vault", values: map[string]string{"servers[1].port": "9090", "hosts[1]": "d", "hosts[0]": "c"}}
+	p, err := NewParser(&cfg, WithSource(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("config", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	want := testStruct{
+		Config: path,
+		Servers: []server{
+			{Host: "a", Port: 8080, Tags: []string{"x", "y"}, Proxy: struct {
+				URL string `config:"name:url"`
+			}{URL: "http://proxy"}},
+			{Host: "b", Port: 9090},
+		},
+		Hosts: []string{"c", "d"},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Parser.Parse() = %+v, want %+v", cfg, want)
+	}
+	if got := p.Snapshot().Values["hosts"]; got != "c,d" {
+		t.Errorf("Parser.Snapshot() hosts = %v, want %v", got, "c,d")
+	}
+}
+
+func Test_splitIndexedKey(t *testing.T) {
+	tests := []struct {
+		key       string
+		wantName  string
+		wantIndex int
+		wantRest  string
+		wantOk    bool
+	}{
+		{key: "servers[0].host", wantName: "servers", wantIndex: 0, wantRest: ".host", wantOk: true},
+		{key: "db.hosts[12]", wantName: "db.hosts", wantIndex: 12, wantOk: true},
+		{key: "servers[1].ports[2]", wantName: "servers", wantIndex: 1, wantRest: ".ports[2]", wantOk: true},
+		{key: "servers"},
+		{key: "servers[x]"},
+		{key: "servers[-1]"},
+		{key: "[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			name, index, rest, ok := splitIndexedKey(tt.key)
+			if name != tt.wantName || index != tt.wantIndex || rest != tt.wantRest || ok != tt.wantOk {
+				t.Errorf("splitIndexedKey() = %v, %v, %v, %v, want %v, %v, %v, %v", name, index, rest, ok, tt.wantName, tt.wantIndex, tt.wantRest, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestWithStrictStruct(t *testing.T) {
+	type nested struct {
+		Host string `config:"name:host"`
+		Port int    `confg:"name:port"`
+	}
+	tests := []struct {
+		name    string
+		in      interface{}
+		opts    []Option
+		wantErr string
+	}{
+		{name: "tagged", in: &struct {
+			Host string `config:"name:host"`
+			Skip string `config:"-"`
+			skip string
+		}{}},
+		{name: "typo", in: &struct {
+			Host string `confg:"name:host"`
+		}{}, wantErr: "Field Host has no config tag. Set `config:\"-\"` to skip it"},
+		{name: "nested typo", in: &struct {
+			DB nested `config:"name:db"`
+		}{}, wantErr: "Field DB.Port has no config tag. Set `config:\"-\"` to skip it"},
+		{name: "untagged struct", in: &struct {
+			DB nested
+		}{}, wantErr: "Field DB has no config tag. Set `config:\"-\"` to skip it"},
+		{name: "json tag", in: &struct {
+			Host string `json:"host"`
+		}{}, opts: []Option{WithJSONTags()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParser(tt.in, append(tt.opts, WithStrictStruct())...)
+			if (err != nil) != (tt.wantErr != "") || err != nil && err.Error() != tt.wantErr {
+				t.Errorf("NewParser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewParser_unexported(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      interface{}
+		wantErr string
+	}{
+		{name: "unexported", in: &struct {
+			port int `config:"name:port"`
+		}{}, wantErr: "Field port has config tag, but it is unexported, so it can't be set"},
+		{name: "nested unexported", in: &struct {
+			DB struct {
+				host string `config:"name:host"`
+			} `config:"name:db"`
+		}{}, wantErr: "Field DB.host has config tag, but it is unexported, so it can't be set"},
+		{name: "ignored", in: &struct {
+			port int `config:"-"`
+		}{}},
+		{name: "untagged", in: &struct {
+			port int
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParser(tt.in)
+			if (err != nil) != (tt.wantErr != "") || err != nil && err.Error() != tt.wantErr {
+				t.Errorf("NewParser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type HTTPTestOptions struct {
+	Port    int    `config:"name:port;default:80"`
+	TimeOut string `config:""`
+}
+
+type TLSTestOptions struct {
+	Cert string `config:"name:cert"`
+}
+
+func TestParser_Parse_embedded(t *testing.T) {
+	type testStruct struct {
+		HTTPTestOptions
+		TLS struct {
+			TLSTestOptions
+		} `config:"name:tls"`
+		Admin struct {
+			HTTPTestOptions `config:"name:http"`
+		} `config:"name:admin"`
+	}
+
+	os.Args = []string{"/app/test", "--port=8080", "--time_out=5s", "--tls.cert=a.pem", "--admin.http.port=9090"}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg, WithStrictStruct())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if cfg.Port != 8080 || cfg.TimeOut != "5s" || cfg.TLS.Cert != "a.pem" || cfg.Admin.Port != 9090 {
+		t.Errorf("Parser.Parse() = %+v", cfg)
+	}
+}
+
+func TestParser_Parse_structPointer(t *testing.T) {
+	type tlsConfig struct {
+		Cert string `config:"name:cert;required"`
+		Key  string `config:"name:key;default:key.pem"`
+	}
+	type testStruct struct {
+		TLS   *tlsConfig `config:"name:tls;mode:cli"`
+		Proxy *struct {
+			URL string `config:"name:url;default:http://proxy"`
+		} `config:"name:proxy;mode:cli"`
+	}
+
+	os.Args = []string{"/app/test", "--tls.cert=cert.pem"}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if cfg.TLS == nil || *cfg.TLS != (tlsConfig{Cert: "cert.pem", Key: "key.pem"}) {
+		t.Errorf("Parser.Parse() TLS = %+v", cfg.TLS)
+	}
+	if cfg.Proxy != nil {
+		t.Errorf("Parser.Parse() Proxy = %+v, want nil", cfg.Proxy)
+	}
+	if _, ok := p.Snapshot().Values["proxy.url"]; ok {
+		t.Errorf("Parser.Snapshot() should not contain values of nil struct")
+	}
+
+	// Required fields of nil struct are not checked
+	os.Args = []string{"/app/test"}
+	if _, err = p.Reload(); err != nil {
+		t.Fatalf("Parser.Reload() error = %v", err)
+	}
+	if cfg.TLS != nil {
+		t.Errorf("Parser.Reload() TLS = %+v, want nil", cfg.TLS)
+	}
+
+	os.Args = []string{"/app/test", "--tls.key=a.pem"}
+	if _, err = p.Reload(); err == nil || err.Error() != "Missing required configs: tls.cert (cli --tls.cert)" {
+		t.Errorf("Parser.Reload() error = %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Address of local Consul agent, used if ConsulSource.Address is empty
+const defaultConsulAddress = "http://127.0.0.1:8500"
+
+// Source that reads all keys under KV prefix of Consul.
+// Key path parts are mapped into nested config names. Ex.: prefix "app/" and key "app/db/host" gives "db.host"
+type ConsulSource struct {
+	Address    string       // Consul agent url. Default is http://127.0.0.1:8500
+	Prefix     string       // KV prefix. Ex.: services/api/
+	Token      string       // ACL token
+	Datacenter string       // Datacenter to query. Default is agent's one
+	Client     *http.Client // Custom http client. Default is http.DefaultClient
+}
+
+// Single entry of Consul KV api response
+type consulKV struct {
+	Key   string
+	Value *string // Base64 encoded, nil for folders
+}
+
+func (s *ConsulSource) Name() string {
+	return "consul"
+}
+
+// Read all keys under prefix with recurse request to Consul KV api
+func (s *ConsulSource) Load(ctx context.Context) (map[string]string, error) {
+	address := s.Address
+	if address == "" {
+		address = defaultConsulAddress
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	query := url.Values{"recurse": {"true"}}
+	if s.Datacenter != "" {
+		query.Set("dc", s.Datacenter)
+	}
+	reqURL := fmt.Sprintf("%s/v1/kv/%s?%s", strings.TrimRight(address, "/"), strings.TrimLeft(s.Prefix, "/"), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.Token != "" {
+		req.Header.Set("X-Consul-Token", s.Token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := make(map[string]string)
+	if resp.StatusCode == http.StatusNotFound { // No keys under prefix
+		return result, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(fmt.Sprintf("Consul responded with %s", resp.Status))
+	}
+
+	var entries []consulKV
+	err = json.NewDecoder(resp.Body).Decode(&entries)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.Value == nil {
+			continue
+		}
+
+		value, err := base64.StdEncoding.DecodeString(*entry.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		name := keyFromPath(entry.Key, strings.TrimLeft(s.Prefix, "/"))
+		if name == "" {
+			continue
+		}
+		result[name] = string(value)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestConsulSource_Load(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("recurse") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/app/":
+			w.Write([]byte(`[
+				{"Key":"app/","Value":null},
+				{"Key":"app/port","Value":"ODA4MA=="},
+				{"Key":"app/db/","Value":null},
+				{"Key":"app/db/host","Value":"bG9jYWxob3N0"}
+			]`))
+		case "/v1/kv/secret/":
+			if r.Header.Get("X-Consul-Token") != "token" || r.URL.Query().Get("dc") != "eu" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`[{"Key":"secret/pass","Value":"cXdlcnR5"}]`))
+		case "/v1/kv/broken/":
+			w.Write([]byte(`[{"Key":"broken/pass","Value":"!!!"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		source  ConsulSource
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "prefix", source: ConsulSource{Address: server.URL, Prefix: "app/"}, want: map[string]string{"port": "8080", "db.host": "localhost"}},
+		{name: "token", source: ConsulSource{Address: server.URL, Prefix: "secret/", Token: "token", Datacenter: "eu"}, want: map[string]string{"pass": "qwerty"}},
+		{name: "forbidden", source: ConsulSource{Address: server.URL, Prefix: "secret/"}, wantErr: true},
+		{name: "empty", source: ConsulSource{Address: server.URL, Prefix: "zzz/"}, want: map[string]string{}},
+		{name: "broken value", source: ConsulSource{Address: server.URL, Prefix: "broken/"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.source.Load(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ConsulSource.Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConsulSource.Load() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
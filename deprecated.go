@@ -0,0 +1,39 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Report every deprecated config that got value not from its default
+func (p *Parser) warnDeprecated() {
+	names := []string{}
+	for _, field := range p.fields {
+		if !field.tags.isDeprecated {
+			continue
+		}
+		if source, ok := p.valueSources[field.tags.name]; ok && source != sourceDefault {
+			names = append(names, field.tags.name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field := p.fieldByConfigName(name)
+		message := fmt.Sprintf("Config %s is deprecated", name)
+		if field.tags.deprecated != "" {
+			message = fmt.Sprintf("%s: %s", message, field.tags.deprecated)
+		}
+		p.warn(errors.New(message))
+	}
+}
+
+// Mark of deprecated field for help. Ex.: "(deprecated: use --new-name instead)"
+func deprecationHint(tags structFieldTags) string {
+	if tags.deprecated == "" {
+		return "(deprecated)"
+	}
+
+	return fmt.Sprintf("(deprecated: %s)", tags.deprecated)
+}
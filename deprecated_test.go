@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParser_Parse_deprecated(t *testing.T) {
+	type testStruct struct {
+		Old    string `config:"name:old;mode:cli;deprecated:use --new instead;desc:Old option"`
+		New    string `config:"name:new;mode:cli;desc:New option"`
+		Legacy int    `config:"name:legacy;mode:cli;deprecated;default:1"`
+		Prefix string `config:"name:prefix;mode:cli"`
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "not used", args: []string{"/app/test", "--new=a"}, want: []string{}},
+		{name: "used", args: []string{"/app/test", "--old=a", "--legacy=2"}, want: []string{"Config legacy is deprecated", "Config old is deprecated: use --new instead"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = tt.args
+
+			got := []string{}
+			var cfg testStruct
+			p, err := NewParser(&cfg, WithWarningHandler(func(err error) {
+				got = append(got, err.Error())
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = p.Parse("", "prefix"); err != nil {
+				t.Fatalf("Parser.Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parser.Parse() warnings = %v, want %v", got, tt.want)
+			}
+			if len(p.Warnings()) != len(tt.want) {
+				t.Errorf("Parser.Warnings() = %v, want %v", p.Warnings(), tt.want)
+			}
+		})
+	}
+
+	var cfg testStruct
+	p, _ := NewParser(&cfg)
+	want := "--new New option (cli only)\n--old Old option (deprecated: use --new instead) (cli only)\n"
+	if got := p.Help(""); got != want {
+		t.Errorf("Parser.Help() = \n%v\n, want \n%v\n", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// Effective configuration at some moment: raw values written into config struct and names of their sources
+// (cli, cfg, env, default or name of external source). Values of fields with secret tag are redacted, as well as
+// secret fields inside of items of slices of structs and json values
+type Snapshot struct {
+	Values  map[string]string
+	Sources map[string]string
+
+	fingerprints map[string]string // Hashes of secret values, so their changes can be detected
+}
+
+// Single changed config. Old or new value is empty if config was added or removed
+type Change struct {
+	Name      string
+	OldValue  string
+	NewValue  string
+	OldSource string
+	NewSource string
+}
+
+// Format change for logging. Ex.: "log_level debug→info"
+func (c Change) String() string {
+	return fmt.Sprintf("%s %s→%s", c.Name, c.OldValue, c.NewValue)
+}
+
+// Return snapshot of effective configuration, made by last Parse or Reload
+func (p *Parser) Snapshot() Snapshot {
+	if p.mu != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+	}
+
+	snapshot := Snapshot{
+		Values:       copyValues(p.values),
+		Sources:      copyValues(p.valueSources),
+		fingerprints: make(map[string]string),
+	}
+	for _, field := range p.fields {
+		value, ok := snapshot.Values[field.tags.name]
+		if shown := p.shownValue(field, value); ok && shown != value {
+			hash := sha256.Sum256([]byte(value))
+			snapshot.fingerprints[field.tags.name] = hex.EncodeToString(hash[:])
+			snapshot.Values[field.tags.name] = shown
+		}
+	}
+
+	return snapshot
+}
+
+// Compare two snapshots. Return added, removed and changed configs (including ones with same value from other source),
+// sorted by name
+func Diff(before, after Snapshot) []Change {
+	changes := []Change{}
+	for name, value := range after.Values {
+		oldValue, ok := before.Values[name]
+		if ok && oldValue == value && before.fingerprints[name] == after.fingerprints[name] && before.Sources[name] == after.Sources[name] {
+			continue
+		}
+		changes = append(changes, Change{
+			Name:      name,
+			OldValue:  oldValue,
+			NewValue:  value,
+			OldSource: before.Sources[name],
+			NewSource: after.Sources[name],
+		})
+	}
+	for name, value := range before.Values {
+		if _, ok := after.Values[name]; !ok {
+			changes = append(changes, Change{Name: name, OldValue: value, OldSource: before.Sources[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
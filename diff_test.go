@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParser_Snapshot(t *testing.T) {
+	type testStruct struct {
+		Port     int    `config:"name:port;mode:env,cli"`
+		Host     string `config:"name:host;mode:cfg;default:localhost"`
+		Level    string `config:"name:level;mode:cli"`
+		Password string `config:"name:password;mode:cfg;secret"`
+	}
+
+	os.Args = []string{"/app/test", "--level=debug"}
+	t.Setenv("PORT", "8080")
+
+	var cfg testStruct
+	src := &staticSource{name: "vault", values: map[string]string{"password": "qwerty"}}
+	p, err := NewParser(&cfg, WithSource(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	before := p.Snapshot()
+	wantValues := map[string]string{"port": "8080", "host": "localhost", "level": "debug", "password": redacted}
+	if !reflect.DeepEqual(before.Values, wantValues) {
+		t.Errorf("Parser.Snapshot() values = %v, want %v", before.Values, wantValues)
+	}
+	wantSources := map[string]string{"port": "env", "host": "default", "level": "cli", "password": "vault"}
+	if !reflect.DeepEqual(before.Sources, wantSources) {
+		t.Errorf("Parser.Snapshot() sources = %v, want %v", before.Sources, wantSources)
+	}
+
+	os.Args = []string{"/app/test", "--level=info", "--port=9090"}
+	src.values = map[string]string{"password": "secret"}
+	if _, err = p.Reload(); err != nil {
+		t.Fatalf("Parser.Reload() error = %v", err)
+	}
+
+	got := Diff(before, p.Snapshot())
+	want := []Change{
+		{Name: "level", OldValue: "debug", NewValue: "info", OldSource: "cli", NewSource: "cli"},
+		{Name: "password", OldValue: redacted, NewValue: redacted, OldSource: "vault", NewSource: "vault"},
+		{Name: "port", OldValue: "8080", NewValue: "9090", OldSource: "env", NewSource: "cli"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before Snapshot
+		after  Snapshot
+		want   []Change
+	}{
+		{
+			name:   "not changed",
+			before: Snapshot{Values: map[string]string{"a": "1"}, Sources: map[string]string{"a": "env"}},
+			after:  Snapshot{Values: map[string]string{"a": "1"}, Sources: map[string]string{"a": "env"}},
+			want:   []Change{},
+		},
+		{
+			name:   "added and removed",
+			before: Snapshot{Values: map[string]string{"a": "1"}, Sources: map[string]string{"a": "env"}},
+			after:  Snapshot{Values: map[string]string{"b": "2"}, Sources: map[string]string{"b": "cfg"}},
+			want: []Change{
+				{Name: "a", OldValue: "1", OldSource: "env"},
+				{Name: "b", NewValue: "2", NewSource: "cfg"},
+			},
+		},
+		{
+			name:   "source changed",
+			before: Snapshot{Values: map[string]string{"a": "1"}, Sources: map[string]string{"a": "env"}},
+			after:  Snapshot{Values: map[string]string{"a": "1"}, Sources: map[string]string{"a": "cli"}},
+			want:   []Change{{Name: "a", OldValue: "1", NewValue: "1", OldSource: "env", NewSource: "cli"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChange_String(t *testing.T) {
+	if got := (Change{Name: "log_level", OldValue: "debug", NewValue: "info"}).String(); got != "log_level debug→info" {
+		t.Errorf("Change.String() = %v", got)
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source that reads mounted directory where each file name is a key and its content is a value.
+// It is the layout of Kubernetes ConfigMap and Secret volumes. Nested directories give nested names,
+// ex.: file "db/host" will be available as "db.host". Hidden files and directories (like "..data") are skipped
+type DirSource struct {
+	Path string // Mounted directory
+}
+
+func (s *DirSource) Name() string {
+	return "dir"
+}
+
+// Read all files of directory recursively. Trailing new line of file content is trimmed
+func (s *DirSource) Load(ctx context.Context) (map[string]string, error) {
+	result := make(map[string]string)
+	err := s.readDir(s.Path, "", result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Read directory files into result. Exist because of recursion in nested directories
+func (s *DirSource) readDir(dir, prefix string, result map[string]string) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		name := entry.Name()
+		if prefix != "" {
+			name = prefix + separatorNested + name
+		}
+
+		// Files of ConfigMap volume are symlinks, so follow them
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			err = s.readDir(path, name, result)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		result[name] = strings.TrimRight(string(content), "\r\n")
+	}
+
+	return nil
+}
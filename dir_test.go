@@ -0,0 +1,60 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDirSource_Load(t *testing.T) {
+	dir := t.TempDir()
+
+	// Same layout as Kubernetes creates for ConfigMap volume
+	files := map[string]string{
+		"..2022_06_01/port":    "8080\n",
+		"..2022_06_01/db/host": "localhost",
+		".hidden":              "zzz",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("..2022_06_01", filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "port"), filepath.Join(dir, "port")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "db"), filepath.Join(dir, "db")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		source  DirSource
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "configmap", source: DirSource{Path: dir}, want: map[string]string{"port": "8080", "db.host": "localhost"}},
+		{name: "not exist", source: DirSource{Path: filepath.Join(dir, "zzz")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.source.Load(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DirSource.Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DirSource.Load() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
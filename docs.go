@@ -0,0 +1,167 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
+
+// Formats of Docs
+const (
+	DocsMarkdown = "markdown"
+	DocsMan      = "man" // OPTIONS section of man page in roff
+)
+
+// Generators of documentation. Keys - formats
+var docsFormats = map[string]func(rows []docsRow) string{
+	DocsMarkdown: markdownDocs,
+	DocsMan:      manDocs,
+}
+
+// Documentation of single option
+type docsRow struct {
+	FieldInfo
+	usage string // Description with hints of deprecation and allowed values
+}
+
+// Return documentation of options in format (ex.: DocsMarkdown), made of the same metadata as help.
+// All fields except hidden ones are documented in order of help: sorted by config name, or in declaration order
+// if WithDeclarationOrder is set
+func (p *Parser) Docs(format string) (string, error) {
+	generate, ok := docsFormats[format]
+	if !ok {
+		return "", errors.New(fmt.Sprintf("Unknown docs format %s. Available formats: %s", format, strings.Join(maps.Keys(docsFormats), ", ")))
+	}
+
+	return generate(p.docsRows()), nil
+}
+
+// Collect documentation of not hidden fields in order of help
+func (p *Parser) docsRows() []docsRow {
+	rows := []docsRow{}
+	for _, field := range p.sortedFields() {
+		if !field.tags.hidden {
+			rows = append(rows, docsRow{FieldInfo: p.fieldInfo(field), usage: describe(field.tags)})
+		}
+	}
+
+	return rows
+}
+
+// Return short and long flags of option. Ex.: "-p", "--port"
+func (r docsRow) flags() []string {
+	result := []string{}
+	if r.Short != "" {
+		result = append(result, r.Short)
+	}
+	if r.Flag != "" {
+		result = append(result, r.Flag)
+	}
+
+	return result
+}
+
+// Generate markdown table of options
+func markdownDocs(rows []docsRow) string {
+	buffer := bytes.NewBufferString("| Flag | Env | File key | Type | Default | Example | Description |\n")
+	buffer.WriteString("|------|-----|----------|------|---------|---------|-------------|\n")
+	for _, row := range rows {
+		flags := []string{}
+		for _, flag := range row.flags() {
+			flags = append(flags, markdownCode(flag))
+		}
+		defaultText := ""
+		if row.HasDefault {
+			defaultText = markdownCode(row.Default)
+		}
+		description := strings.TrimSpace(row.usage + " " + row.LongDescription)
+		buffer.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s |\n",
+			strings.Join(flags, ", "),
+			markdownCode(row.Env),
+			markdownCode(row.Key),
+			markdownCode(row.Type),
+			defaultText,
+			markdownCode(row.Example),
+			markdownEscape(description),
+		))
+	}
+
+	return buffer.String()
+}
+
+// Format text as markdown code span for table cell. Empty text gives empty cell
+func markdownCode(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	return "`" + markdownEscape(text) + "`"
+}
+
+// Escape text for markdown table cell
+func markdownEscape(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
+
+// Generate OPTIONS section of man page in roff. Each option is tagged paragraph with flags (or env variable or
+// config file key, if field has no flag) and type, followed by description and details
+func manDocs(rows []docsRow) string {
+	buffer := bytes.NewBufferString(".SH OPTIONS\n")
+	for _, row := range rows {
+		names := row.flags()
+		if len(names) == 0 {
+			names = []string{firstNonEmpty(row.Env, row.Key)}
+		}
+		for i, name := range names {
+			names[i] = fmt.Sprintf("\\fB%s\\fR", roffEscape(name))
+		}
+		buffer.WriteString(".TP\n")
+		buffer.WriteString(strings.Join(names, ", "))
+		if row.Type != "" {
+			buffer.WriteString(fmt.Sprintf(" \\fI%s\\fR", roffEscape(row.Type)))
+		}
+		buffer.WriteString("\n")
+
+		details := []string{}
+		if row.HasDefault {
+			details = append(details, fmt.Sprintf("Default: %s.", roffEscape(row.Default)))
+		}
+		if row.Example != "" {
+			details = append(details, fmt.Sprintf("Example: %s.", roffEscape(row.Example)))
+		}
+		if row.Env != "" {
+			details = append(details, fmt.Sprintf("Env: \\fB%s\\fR.", roffEscape(row.Env)))
+		}
+		if row.Key != "" {
+			details = append(details, fmt.Sprintf("Config file key: \\fB%s\\fR.", roffEscape(row.Key)))
+		}
+		lines := []string{}
+		for _, text := range []string{roffEscape(row.usage), roffEscape(row.LongDescription), strings.Join(details, " ")} {
+			if text != "" {
+				lines = append(lines, roffLine(text))
+			}
+		}
+		if len(lines) > 0 {
+			buffer.WriteString(strings.Join(lines, "\n.br\n") + "\n")
+		}
+	}
+
+	return buffer.String()
+}
+
+// Escape text for roff: backslashes and dashes are escaped, so dashes are not turned into hyphens
+func roffEscape(text string) string {
+	return strings.NewReplacer(`\`, `\e`, "-", `\-`, "\n", " ").Replace(text)
+}
+
+// Format text as roff text line. Line starting with control character is protected with zero-width space
+func roffLine(text string) string {
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		return `\&` + text
+	}
+
+	return text
+}
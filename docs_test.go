@@ -0,0 +1,65 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestParser_Docs(t *testing.T) {
+	type testStruct struct {
+		Port     int      `config:"name:port;short:p;default:80;desc:Port to listen;example:8080"`
+		Level    string   `config:"name:level;mode:cli,env;oneof:debug,info;desc:Log level"`
+		Password string   `config:"name:password;mode:cfg;secret;default:qwerty"`
+		Hosts    []string `config:"name:hosts;mode:env;deprecated:use peers"`
+		Internal bool     `config:"name:internal;hidden"`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "| Flag | Env | File key | Type | Default | Example | Description |\n" +
+		"|------|-----|----------|------|---------|---------|-------------|\n" +
+		"|  | `HOSTS` |  | `[]string` |  |  | (deprecated: use peers) |\n" +
+		"| `--level` | `LEVEL` |  | `string` |  |  | Log level [debug\\|info] |\n" +
+		"|  |  | `password` | `string` | `****` |  |  |\n" +
+		"| `-p`, `--port` | `PORT` | `port` | `int` | `80` | `8080` | Port to listen |\n"
+	got, err := p.Docs(DocsMarkdown)
+	if err != nil {
+		t.Fatalf("Parser.Docs() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("Parser.Docs() = \n%v\n, want \n%v", got, want)
+	}
+
+	if _, err = p.Docs("pdf"); err == nil {
+		t.Errorf("Parser.Docs() should fail with unknown format")
+	}
+}
+
+func TestParser_Docs_man(t *testing.T) {
+	type testStruct struct {
+		Port  int    `config:"name:port;short:p;default:80;desc:Port to listen"`
+		Level string `config:"name:log-level;mode:env;desc:.Log level;longdesc:Affects all loggers"`
+		Token string `config:"name:token;mode:cfg;example:a-b"`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ".SH OPTIONS\n" +
+		".TP\n\\fBLOG\\-LEVEL\\fR \\fIstring\\fR\n\\&.Log level\n.br\nAffects all loggers\n.br\nEnv: \\fBLOG\\-LEVEL\\fR.\n" +
+		".TP\n\\fB\\-p\\fR, \\fB\\-\\-port\\fR \\fIint\\fR\nPort to listen\n.br\nDefault: 80. Env: \\fBPORT\\fR. Config file key: \\fBport\\fR.\n" +
+		".TP\n\\fBtoken\\fR \\fIstring\\fR\nExample: a\\-b. Config file key: \\fBtoken\\fR.\n"
+	got, err := p.Docs(DocsMan)
+	if err != nil {
+		t.Fatalf("Parser.Docs() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("Parser.Docs() = \n%v\n, want \n%v", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Base name of dotenv files. Ex.: .env, .env.local, .env.prod
+const dotenvFile = ".env"
+
+// Load env variables from dotenv files of directory (working directory if empty) in conventional order:
+// .env, .env.local, .env.<profile>, .env.<profile>.local. Later files override earlier ones, and variables
+// set in environment override all of them. Missing files are skipped. Profile files are skipped if profile is empty
+func WithDotenv(dir, profile string) Option {
+	return func(p *Parser) {
+		p.useDotenv = true
+		p.dotenvDir = dir
+		p.dotenvProfile = profile
+	}
+}
+
+// Return paths of dotenv files in order of loading
+func dotenvFiles(dir, profile string) []string {
+	names := []string{dotenvFile, dotenvFile + ".local"}
+	if profile != "" {
+		names = append(names, dotenvFile+"."+profile, dotenvFile+"."+profile+".local")
+	}
+
+	result := []string{}
+	for _, name := range names {
+		result = append(result, filepath.Join(dir, name))
+	}
+
+	return result
+}
+
+// Read dotenv files set by WithDotenv into parser values
+func (p *Parser) parseDotenv() error {
+	p.dotenv = nil
+	if !p.useDotenv {
+		return nil
+	}
+
+	p.dotenv = make(map[string]string)
+	for _, path := range dotenvFiles(p.dotenvDir, p.dotenvProfile) {
+		content, err := ioutil.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		values, err := parseDotenv(string(content))
+		if err != nil {
+			return fmt.Errorf("Cannot parse %s: %w", path, err)
+		}
+		for k, v := range values {
+			p.dotenv[k] = v
+		}
+	}
+
+	return nil
+}
+
+// Parse content of dotenv file. Supported lines: KEY=value, export KEY=value, comments starting with "#".
+// Values can be single-quoted (taken as is) or double-quoted (with \n, \t, \" and \\ escapes).
+// Unquoted values are trimmed, and text after " #" is comment
+func parseDotenv(content string) (map[string]string, error) {
+	result := make(map[string]string)
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, errors.New(fmt.Sprintf("Wrong line %d. Should be KEY=value", i+1))
+		}
+
+		value, err := parseDotenvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Wrong value of %s at line %d: %s", key, i+1, err))
+		}
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+// Unquote value of dotenv line
+func parseDotenvValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	quote := value[0]
+	if quote != '"' && quote != '\'' {
+		if index := strings.Index(value, " #"); index >= 0 {
+			value = value[:index]
+		}
+		return strings.TrimSpace(value), nil
+	}
+
+	end := -1
+	for i := 1; i < len(value); i++ {
+		if quote == '"' && value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] == quote {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return "", errors.New("quote is not closed")
+	}
+	if rest := strings.TrimSpace(value[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", errors.New("unexpected text after quote")
+	}
+
+	value = value[1:end]
+	if quote == '\'' {
+		return value, nil
+	}
+
+	replacer := strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+	return replacer.Replace(value), nil
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_parseDotenv(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "plain",
+			content: "# Comment\n\nHOST=localhost\nexport PORT = 8080 \nEMPTY=\nURL=http://a/b#c # comment\n",
+			want:    map[string]string{"HOST": "localhost", "PORT": "8080", "EMPTY": "", "URL": "http://a/b#c"},
+		},
+		{
+			name:    "quoted",
+			content: "SINGLE='a \\n # b'\nDOUBLE=\"a\\n\\\"b\\\"\" # comment\nEQUAL=\"x=y\"",
+			want:    map[string]string{"SINGLE": "a \\n # b", "DOUBLE": "a\n\"b\"", "EQUAL": "x=y"},
+		},
+		{name: "no value", content: "HOST", wantErr: true},
+		{name: "wrong key", content: "DB HOST=a", wantErr: true},
+		{name: "not closed", content: "HOST=\"a", wantErr: true},
+		{name: "text after quote", content: "HOST='a' b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDotenv(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseDotenv() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDotenv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithDotenv(t *testing.T) {
+	type testStruct struct {
+		Host   string            `config:"name:host"`
+		Port   int               `config:"name:port"`
+		User   string            `config:"name:user"`
+		Level  string            `config:"name:level"`
+		Labels map[string]string `config:"name:labels"`
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		".env":            "HOST=base\nPORT=80\nUSER=base\nLEVEL=info\nLABELS_TEAM=core\n",
+		".env.local":      "HOST=local\n",
+		".env.prod":       "PORT=443\nHOST=prod\n",
+		".env.prod.local": "PORT=8443\n",
+		".env.test":       "PORT=1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	os.Args = []string{"/app/test"}
+	t.Setenv("LEVEL", "debug")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg, WithDotenv(dir, "prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	want := testStruct{Host: "prod", Port: 8443, User: "base", Level: "debug", Labels: map[string]string{"team": "core"}}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Parser.Parse() = %+v, want %+v", cfg, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".env.local"), []byte("HOST"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = p.Reload(); err == nil {
+		t.Errorf("Parser.Reload() should fail with broken dotenv file")
+	}
+}
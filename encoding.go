@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
+
+// Available values of encoding tag
+const (
+	encodingHex  = "hex"
+	encodingJSON = "json"
+)
+
+// Keys - available encodings, values - decoders that put raw value into field
+var encodings = map[string]func(field reflect.Value, value string, tags structFieldTags) error{
+	encodingHex:  writeHexToField,
+	encodingJSON: writeJSONToField,
+}
+
+// Check encoding name of tag
+func parseEncodingTag(value string) (string, error) {
+	if _, ok := encodings[value]; !ok {
+		return "", errors.New(fmt.Sprintf("Unknown encoding %s. Available encodings: %s", value, strings.Join(maps.Keys(encodings), ", ")))
+	}
+
+	return value, nil
+}
+
+// Decode hex value into []byte or [N]byte field. Length of array field should match length of decoded value
+func writeHexToField(field reflect.Value, value string, tags structFieldTags) error {
+	if (field.Kind() != reflect.Slice && field.Kind() != reflect.Array) || field.Type().Elem().Kind() != reflect.Uint8 {
+		return errors.New(fmt.Sprintf("Hex encoding of %s is supported just for []byte and [N]byte fields", tags.name))
+	}
+
+	decoded, err := hex.DecodeString(value)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Wrong hex value of %s: %s", tags.name, err))
+	}
+
+	if field.Kind() == reflect.Slice {
+		field.SetBytes(decoded)
+		return nil
+	}
+
+	if len(decoded) != field.Len() {
+		return errors.New(fmt.Sprintf("Wrong length of %s: %d bytes, want %d", tags.name, len(decoded), field.Len()))
+	}
+	reflect.Copy(field, reflect.ValueOf(decoded))
+
+	return nil
+}
+
+// Decode json value into field of any type. Field is not changed if value is broken
+func writeJSONToField(field reflect.Value, value string, tags structFieldTags) error {
+	decoded := reflect.New(field.Type())
+	err := json.Unmarshal([]byte(value), decoded.Interface())
+	if err != nil {
+		return errors.New(fmt.Sprintf("Wrong json value of %s: %s", tags.name, err))
+	}
+	field.Set(decoded.Elem())
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_writeHexToField(t *testing.T) {
+	type args struct {
+		Slice  []byte
+		Array  [4]byte
+		String string
+	}
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		want    args
+		wantErr bool
+	}{
+		{name: "slice", key: "Slice", value: "deadbeef", want: args{Slice: []byte{0xde, 0xad, 0xbe, 0xef}}},
+		{name: "array", key: "Array", value: "DEADBEEF", want: args{Array: [4]byte{0xde, 0xad, 0xbe, 0xef}}},
+		{name: "array length", key: "Array", value: "dead", wantErr: true},
+		{name: "wrong hex", key: "Slice", value: "ZZ", wantErr: true},
+		{name: "wrong type", key: "String", value: "dead", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got args
+			err := writeHexToField(reflect.ValueOf(&got).Elem().FieldByName(tt.key), tt.value, structFieldTags{name: "key"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("writeHexToField() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("writeHexToField() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_encoding(t *testing.T) {
+	type testStruct struct {
+		Key []byte `config:"name:key;mode:cli;encoding:hex"`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.parsedCli = map[string]string{"key": "0102"}
+	if err = p.fillStructWithValues(&cfg, ""); err != nil {
+		t.Fatalf("Parser.fillStructWithValues() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Key, []byte{1, 2}) {
+		t.Errorf("Parser.fillStructWithValues() = %v, want %v", cfg.Key, []byte{1, 2})
+	}
+
+	var wrong struct {
+		Key []byte `config:"name:key;encoding:zzz"`
+	}
+	if _, err = NewParser(&wrong); err == nil {
+		t.Errorf("NewParser() should fail with unknown encoding")
+	}
+}
+
+func Test_writeJSONToField(t *testing.T) {
+	type features struct {
+		A bool `json:"a"`
+		B int  `json:"b"`
+	}
+	type args struct {
+		Struct features
+		Map    map[string]int
+	}
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		want    args
+		wantErr bool
+	}{
+		{name: "struct", key: "Struct", value: `{"a":true,"b":2}`, want: args{Struct: features{A: true, B: 2}}},
+		{name: "map", key: "Map", value: `{"a":1}`, want: args{Map: map[string]int{"a": 1}}},
+		{name: "wrong json", key: "Struct", value: `{"a":`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got args
+			err := writeJSONToField(reflect.ValueOf(&got).Elem().FieldByName(tt.key), tt.value, structFieldTags{name: "key"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("writeJSONToField() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("writeJSONToField() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_jsonEncoding(t *testing.T) {
+	type testStruct struct {
+		Features struct {
+			A bool `json:"a"`
+		} `config:"name:features;encoding:json"`
+		Limits map[string]int `config:"name:limits;encoding:json"`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.fields["Features"]; !ok {
+		t.Fatalf("NewParser() should register json encoded struct as single field")
+	}
+	p.parsedCfg = make(map[string]string)
+	p.saveToParsed(map[string]interface{}{"features": `{"a":true}`, "limits": map[string]interface{}{"rps": 10}}, "")
+	if err = p.fillStructWithValues(&cfg, ""); err != nil {
+		t.Fatalf("Parser.fillStructWithValues() error = %v", err)
+	}
+	if !cfg.Features.A || !reflect.DeepEqual(cfg.Limits, map[string]int{"rps": 10}) {
+		t.Errorf("Parser.fillStructWithValues() = %+v", cfg)
+	}
+}
@@ -0,0 +1,400 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// Suffix of env variable that contains path to file with value instead of value itself. Ex.: DB_PASS_FILE=/run/secrets/db_pass
+	envFileSuffix = "_FILE"
+	// Mark of env tag value that disables env prefix. Ex.: `env:!HOME`
+	envNoPrefixMark = "!"
+	// Separator of slice items read from indexed env variables. Env values can't contain it
+	separatorEnvItems = "\x00"
+	// Default replacement of nested separator in env variable names. Ex.: db.host gives DB_HOST
+	defaultEnvNestedSeparator = "_"
+)
+
+// Case of env variable names
+type EnvCase string
+
+// Available cases of env variable names
+const (
+	EnvCaseUpper EnvCase = "upper" // Names are uppercased: db_host gives DB_HOST. Default
+	EnvCaseLower EnvCase = "lower" // Names are lowercased: db_host gives db_host
+	EnvCaseExact EnvCase = "exact" // Names are used as they are set in tags and prefix
+)
+
+// Set case of env variable names for all fields. Fields can have own case with envcase tag
+func WithEnvCase(envCase EnvCase) Option {
+	return func(p *Parser) {
+		p.envCase = envCase
+	}
+}
+
+// Parse value of envcase tag
+func parseEnvCaseTag(value string) (EnvCase, error) {
+	switch envCase := EnvCase(value); envCase {
+	case EnvCaseUpper, EnvCaseLower, EnvCaseExact:
+		return envCase, nil
+	}
+
+	return "", errors.New(fmt.Sprintf("Wrong value %s of tag %s. Available values: %s, %s, %s", value, tagEnvCase, EnvCaseUpper, EnvCaseLower, EnvCaseExact))
+}
+
+// Return case of env variable names of field
+func (p *Parser) fieldEnvCase(tags structFieldTags) EnvCase {
+	if tags.envCase != "" {
+		return tags.envCase
+	}
+
+	return p.envCase
+}
+
+// Convert env variable name into case. Default case is upper
+func applyEnvCase(key string, envCase EnvCase) string {
+	switch envCase {
+	case EnvCaseExact:
+		return key
+	case EnvCaseLower:
+		return strings.ToLower(key)
+	}
+
+	return strings.ToUpper(key)
+}
+
+// Look for env variables with listed prefixes too, if they are not found with prefix set by Parse.
+// Prefixes are tried in order, so renamed service can keep honoring old variables. Ex.: "MYAPP_", "LEGACYAPP_"
+func WithEnvPrefixes(prefixes ...string) Option {
+	return func(p *Parser) {
+		p.envPrefixes = append(p.envPrefixes, prefixes...)
+	}
+}
+
+// Set replacement of nested separator (see WithNestedSeparator) in env variable names. Default is "_", so db.host is looked up as DB_HOST
+func WithEnvNestedSeparator(sep string) Option {
+	return func(p *Parser) {
+		p.envNestedSeparator = sep
+	}
+}
+
+// Set separator of list items in env values (ex.: ':' for PATH-like variables). Fields with sep tag keep their own separator
+func WithEnvListSeparator(r rune) Option {
+	return func(p *Parser) {
+		p.envListSeparator = string(r)
+	}
+}
+
+// Read json document from env variable with given name (ex.: "APP_CONFIG") and parse it like config file.
+// Its values override values of config file. Env prefix is not added to the name
+func WithEnvJSON(name string) Option {
+	return func(p *Parser) {
+		p.envJSON = name
+	}
+}
+
+// Set function used to look up env variables instead of os.LookupEnv. Ex.: fake environment in tests.
+// Map items can't be gathered from env variables with field prefix, because such function can't list variables
+func WithLookupEnv(lookup func(key string) (string, bool)) Option {
+	return func(p *Parser) {
+		p.lookupEnvFunc = lookup
+	}
+}
+
+// Capture environment once at start of Parse or Reload, and resolve all fields from that snapshot.
+// It guarantees consistent results even if other goroutines change environment while parsing
+func WithEnvSnapshot() Option {
+	return func(p *Parser) {
+		p.snapshotEnv = true
+	}
+}
+
+// Save snapshot of environment, if it is enabled by WithEnvSnapshot
+func (p *Parser) captureEnv() {
+	if !p.snapshotEnv || p.lookupEnvFunc != nil {
+		return
+	}
+
+	p.envSnapshot = make(map[string]string)
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		p.envSnapshot[key] = value
+	}
+}
+
+// Look up env variable with function set by WithLookupEnv, in snapshot or with os.LookupEnv.
+// Values of dotenv files are used if variable is not set
+func (p *Parser) getenv(key string) (string, bool) {
+	var value string
+	var ok bool
+	switch {
+	case p.lookupEnvFunc != nil:
+		value, ok = p.lookupEnvFunc(key)
+	case p.envSnapshot != nil:
+		value, ok = p.envSnapshot[key]
+	default:
+		value, ok = os.LookupEnv(key)
+	}
+	if !ok {
+		value, ok = p.dotenv[key]
+	}
+
+	return value, ok
+}
+
+// Return all env variables (including ones from dotenv files) as key=value items.
+// Just dotenv variables are returned if env is looked up with custom function
+func (p *Parser) environ() []string {
+	result := []string{}
+	switch {
+	case p.lookupEnvFunc != nil:
+	case p.envSnapshot != nil:
+		for key, value := range p.envSnapshot {
+			result = append(result, key+"="+value)
+		}
+	default:
+		result = os.Environ()
+	}
+
+	set := make(map[string]bool)
+	for _, env := range result {
+		key, _, _ := strings.Cut(env, "=")
+		set[key] = true
+	}
+	for key, value := range p.dotenv {
+		if !set[key] {
+			result = append(result, key+"="+value)
+		}
+	}
+
+	return result
+}
+
+// Convert path of struct field into config name: CamelCase parts are turned into snake_case and joined by "_".
+// Ex.: "DB.MaxConns" gives "db_max_conns", "HTTPPort" gives "http_port"
+func autoName(path string) string {
+	parts := strings.Split(path, separatorNested)
+	for i, part := range parts {
+		runes := []rune(part)
+		var b strings.Builder
+		for j, r := range runes {
+			if j > 0 && unicode.IsUpper(r) {
+				prev := runes[j-1]
+				nextLower := j+1 < len(runes) && unicode.IsLower(runes[j+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					b.WriteRune('_')
+				}
+			}
+			b.WriteRune(unicode.ToLower(r))
+		}
+		parts[i] = b.String()
+	}
+
+	return strings.Join(parts, "_")
+}
+
+// Name of env variable for config name
+func (p *Parser) envKey(name string) string {
+	return p.formatEnvKey(p.envPrefix, name, p.envCase)
+}
+
+// Build name of env variable from prefix and config name. Name is normalized, nested separator is replaced
+// and case is applied
+func (p *Parser) formatEnvKey(prefix, name string, envCase EnvCase) string {
+	sep := p.envNestedSeparator
+	if sep == "" {
+		sep = defaultEnvNestedSeparator
+	}
+
+	if p.normalizeKey != nil {
+		name = p.normalizeKey(name)
+	}
+
+	return applyEnvCase(fmt.Sprintf("%s%s", prefix, strings.ReplaceAll(name, p.nestedSeparator(), sep)), envCase)
+}
+
+// Names of env variables of field: for env name (or config name) and aliases, with each env prefix, in priority order.
+// Env prefix is not added for fields with noprefix tag
+func (p *Parser) envKeys(tags structFieldTags) []string {
+	names := append([]string{tags.envName()}, tags.aliases...)
+	if tags.noPrefix {
+		return p.envKeysWithPrefixes(names, []string{""}, p.fieldEnvCase(tags))
+	}
+
+	return p.envKeysWithPrefixes(names, p.allEnvPrefixes(), p.fieldEnvCase(tags))
+}
+
+// Names of env variables for config names with each prefix. All names with first prefix go first
+func (p *Parser) envKeysWithPrefixes(names, prefixes []string, envCase EnvCase) []string {
+	result := []string{}
+	for _, prefix := range prefixes {
+		for _, name := range names {
+			result = append(result, p.formatEnvKey(prefix, name, envCase))
+		}
+	}
+
+	return result
+}
+
+// Return env prefixes in priority order: one set by Parse (even empty one), then ones set by WithEnvPrefixes.
+// Repeated prefixes are skipped, so result is never empty
+func (p *Parser) allEnvPrefixes() []string {
+	result := []string{p.envPrefix}
+	for _, prefix := range p.envPrefixes {
+		isRepeated := false
+		for _, added := range result {
+			isRepeated = isRepeated || strings.EqualFold(prefix, added)
+		}
+		if !isRepeated {
+			result = append(result, prefix)
+		}
+	}
+
+	return result
+}
+
+// Look for env variable of config name. Variable itself has priority over value read by NAME_FILE variable
+func (p *Parser) lookupEnv(name string) (string, bool) {
+	return p.lookupEnvKey(p.envKey(name))
+}
+
+// Look for env variable by its full name. Variable itself has priority over value read by NAME_FILE variable
+func (p *Parser) lookupEnvKey(key string) (string, bool) {
+	if value, ok := p.getenv(key); ok {
+		return value, true
+	}
+
+	value, ok := p.parsedEnv[key]
+	return value, ok
+}
+
+// Parse json document from env variable set by WithEnvJSON into config file values
+func (p *Parser) parseEnvJSON() error {
+	if p.envJSON == "" {
+		return nil
+	}
+	content, ok := p.getenv(p.envJSON)
+	if !ok || strings.TrimSpace(content) == "" {
+		return nil
+	}
+
+	if p.parsedCfg == nil {
+		p.parsedCfg = make(map[string]string)
+	}
+	err := p.decodeCfg([]byte(content), ".json")
+	if err != nil {
+		return fmt.Errorf("Cannot parse %s: %w", p.envJSON, err)
+	}
+
+	return nil
+}
+
+// Read values of env variables with _FILE suffix (Docker secrets convention) for all fields with env mode
+func (p *Parser) parseEnvFiles() error {
+	p.parsedEnv = make(map[string]string)
+
+	for _, field := range p.fields {
+		if field.tags.mode != 0 && field.tags.mode&modeEnv == 0 {
+			continue
+		}
+
+		suffix := applyEnvCase(envFileSuffix, p.fieldEnvCase(field.tags))
+		for _, key := range p.envKeys(field.tags) {
+			if _, ok := p.getenv(key); ok {
+				continue
+			}
+
+			path, ok := p.getenv(key + suffix)
+			if !ok {
+				continue
+			}
+
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("Cannot read %s%s: %w", key, suffix, err)
+			}
+			p.parsedEnv[key] = strings.TrimRight(string(content), "\r\n")
+		}
+	}
+
+	return nil
+}
+
+// Look for items of slice or map field in separate env variables, which are used when items contain separator:
+// slice items in indexed variables (ex.: HOSTS_0, HOSTS_1), map items in variables with field prefix
+// (ex.: LABELS_TEAM=core gives team=core). Indexes start from 0 and should go without gaps.
+// Items are joined by separatorEnvItems, items of struct slices (json objects) are joined into json array
+func (p *Parser) lookupEnvItems(field *structField) (string, bool) {
+	if field.tags.mode != 0 && field.tags.mode&modeEnv == 0 || field.tags.encoding != "" {
+		return "", false
+	}
+	t := p.fieldType(field)
+	if t == nil || (t.Kind() != reflect.Slice && t.Kind() != reflect.Map) {
+		return "", false
+	}
+
+	for _, key := range p.envKeys(field.tags) {
+		items := []string{}
+		if t.Kind() == reflect.Map {
+			items = p.envMapItems(field, key)
+		}
+		for i := 0; t.Kind() == reflect.Slice; i++ {
+			value, ok := p.lookupEnvKey(fmt.Sprintf("%s_%d", key, i))
+			if !ok {
+				break
+			}
+			items = append(items, value)
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		if t.Kind() == reflect.Slice && isNestedStruct(t.Elem()) {
+			return "[" + strings.Join(items, ",") + "]", true
+		}
+		return strings.Join(items, separatorEnvItems), true
+	}
+
+	return "", false
+}
+
+// Return sorted key=value items of env variables with KEY_ prefix. Keys are remainders of names, lowercased.
+// Variables of other fields under the same prefix (ex.: DB_HOST of db.host for map field db) are not items
+func (p *Parser) envMapItems(field *structField, key string) []string {
+	otherKeys := []string{}
+	for _, other := range p.fields {
+		if other == field || other.tags.mode != 0 && other.tags.mode&modeEnv == 0 {
+			continue
+		}
+		for _, otherKey := range p.envKeys(other.tags) {
+			if strings.HasPrefix(otherKey, key+"_") {
+				otherKeys = append(otherKeys, otherKey)
+			}
+		}
+	}
+
+	items := []string{}
+Names:
+	for _, env := range p.environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, key+"_") || len(name) == len(key)+1 {
+			continue
+		}
+		for _, otherKey := range otherKeys { // Own variable of other field, or its items and file suffix
+			if name == otherKey || strings.HasPrefix(name, otherKey+"_") {
+				continue Names
+			}
+		}
+		items = append(items, strings.ToLower(strings.TrimPrefix(name, key+"_"))+separatorPair+value)
+	}
+	sort.Strings(items)
+
+	return items
+}
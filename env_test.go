@@ -0,0 +1,632 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParser_parseEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "db_pass")
+	if err := os.WriteFile(secret, []byte("qwerty\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("APP_DB_PASS_FILE", secret)
+	t.Setenv("APP_DB_USER", "root")
+	t.Setenv("APP_DB_USER_FILE", secret)
+	t.Setenv("APP_CLI_PASS_FILE", secret)
+	t.Setenv("APP_BROKEN_FILE", filepath.Join(dir, "zzz"))
+
+	tests := []struct {
+		name    string
+		fields  map[string]*structField
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "file",
+			fields: map[string]*structField{
+				"DbPass": {name: "DbPass", tags: structFieldTags{name: "db_pass"}},
+				"DbHost": {name: "DbHost", tags: structFieldTags{name: "db_host", mode: modeEnv}},
+			},
+			want: map[string]string{"APP_DB_PASS": "qwerty"},
+		},
+		{
+			name: "env has priority",
+			fields: map[string]*structField{
+				"DbUser": {name: "DbUser", tags: structFieldTags{name: "db_user"}},
+			},
+			want: map[string]string{},
+		},
+		{
+			name: "not env mode",
+			fields: map[string]*structField{
+				"CliPass": {name: "CliPass", tags: structFieldTags{name: "cli_pass", mode: modeCli}},
+			},
+			want: map[string]string{},
+		},
+		{
+			name: "not exist",
+			fields: map[string]*structField{
+				"Broken": {name: "Broken", tags: structFieldTags{name: "broken", mode: modeEnv}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{fields: tt.fields, envPrefix: "app_"}
+			err := p.parseEnvFiles()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parser.parseEnvFiles() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(tt.want, p.parsedEnv) {
+				t.Errorf("Parser.parseEnvFiles() = %v, want %v", p.parsedEnv, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_lookupEnv(t *testing.T) {
+	t.Setenv("APP_KEY", "env")
+
+	p := &Parser{envPrefix: "app_", parsedEnv: map[string]string{"APP_KEY": "file", "APP_SECRET": "file"}}
+	if got, ok := p.lookupEnv("key"); !ok || got != "env" {
+		t.Errorf("Parser.lookupEnv() = %v, %v, want %v", got, ok, "env")
+	}
+	if got, ok := p.lookupEnv("secret"); !ok || got != "file" {
+		t.Errorf("Parser.lookupEnv() = %v, %v, want %v", got, ok, "file")
+	}
+	if _, ok := p.lookupEnv("zzz"); ok {
+		t.Errorf("Parser.lookupEnv() should not find zzz")
+	}
+}
+
+func Test_autoName(t *testing.T) {
+	tests := map[string]string{
+		"Port":          "port",
+		"MaxConns":      "max_conns",
+		"HTTPPort":      "http_port",
+		"APIKey2":       "api_key2",
+		"ID":            "id",
+		"DB.MaxConns":   "db_max_conns",
+		"Server.TLS.CA": "server_tls_ca",
+	}
+	for path, want := range tests {
+		if got := autoName(path); got != want {
+			t.Errorf("autoName(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestParser_Parse_autoName(t *testing.T) {
+	type testStruct struct {
+		Prefix   string `config:"name:prefix;mode:cli"`
+		HTTPPort int    `config:"mode:env"`
+		DB       struct {
+			MaxConns int    `config:""`
+			Host     string `config:"name:db_host"`
+		} `config:"mode:env"`
+	}
+
+	os.Args = []string{"/app/test", "--prefix=app_"}
+	t.Setenv("APP_HTTP_PORT", "8080")
+	t.Setenv("APP_DB_MAX_CONNS", "10")
+	t.Setenv("APP_DB_HOST", "localhost")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", "prefix"); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if cfg.HTTPPort != 8080 || cfg.DB.MaxConns != 10 || cfg.DB.Host != "localhost" {
+		t.Errorf("Parser.Parse() = %+v", cfg)
+	}
+}
+
+func TestParser_Parse_envTag(t *testing.T) {
+	type testStruct struct {
+		Prefix   string `config:"name:prefix;mode:cli"`
+		DbURL    string `config:"name:db_url;env:DATABASE_URL"`
+		Password string `config:"name:db_pass;env:DATABASE_PASSWORD;required"`
+		Port     int    `config:"name:port;env:PORT;alias:http_port"`
+	}
+
+	secret := filepath.Join(t.TempDir(), "db_pass")
+	if err := os.WriteFile(secret, []byte("qwerty\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Args = []string{"/app/test", "--prefix=app_"}
+	t.Setenv("APP_DATABASE_URL", "postgres://localhost")
+	t.Setenv("APP_DB_URL", "zzz")
+	t.Setenv("APP_DATABASE_PASSWORD_FILE", secret)
+	t.Setenv("APP_HTTP_PORT", "8080")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", "prefix"); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	want := testStruct{Prefix: "app_", DbURL: "postgres://localhost", Password: "qwerty", Port: 8080}
+	if cfg != want {
+		t.Errorf("Parser.Parse() = %+v, want %+v", cfg, want)
+	}
+
+	if got := p.expectedSources(p.fields["DbURL"]); got != "cli --db_url, cfg db_url, env APP_DATABASE_URL" {
+		t.Errorf("Parser.expectedSources() = %v", got)
+	}
+
+	var wrong struct {
+		DbURL string `config:"name:db_url;env:"`
+	}
+	if _, err = NewParser(&wrong); err == nil {
+		t.Errorf("NewParser() should fail with empty env tag")
+	}
+}
+
+func TestParser_Parse_noPrefix(t *testing.T) {
+	type testStruct struct {
+		Prefix string `config:"name:prefix;mode:cli"`
+		Home   string `config:"name:home;noprefix"`
+		Port   int    `config:"name:port;noprefix:false"`
+		Region string `config:"name:region;env:!AWS_REGION"`
+	}
+
+	os.Args = []string{"/app/test", "--prefix=app_"}
+	t.Setenv("HOME", "/home/app")
+	t.Setenv("PORT", "80")
+	t.Setenv("APP_PORT", "8080")
+	t.Setenv("AWS_REGION", "eu-west-1")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", "prefix"); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	want := testStruct{Prefix: "app_", Home: "/home/app", Port: 8080, Region: "eu-west-1"}
+	if cfg != want {
+		t.Errorf("Parser.Parse() = %+v, want %+v", cfg, want)
+	}
+	if got := p.envKeys(p.fields["Region"].tags); !reflect.DeepEqual(got, []string{"AWS_REGION"}) {
+		t.Errorf("Parser.envKeys() = %v", got)
+	}
+}
+
+func TestWithEnvPrefixes(t *testing.T) {
+	type testStruct struct {
+		Prefix string `config:"name:prefix;mode:cli"`
+		Host   string `config:"name:host"`
+		Port   int    `config:"name:port;alias:http_port"`
+		User   string `config:"name:user"`
+		Home   string `config:"name:home;noprefix"`
+	}
+
+	os.Args = []string{"/app/test", "--prefix=myapp_"}
+	t.Setenv("MYAPP_HOST", "new")
+	t.Setenv("LEGACYAPP_HOST", "old")
+	t.Setenv("LEGACYAPP_PORT", "8080")
+	t.Setenv("LEGACYAPP_USER", "root")
+	t.Setenv("HOME", "/home/app")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg, WithEnvPrefixes("MYAPP_", "LEGACYAPP_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", "prefix"); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	want := testStruct{Prefix: "myapp_", Host: "new", Port: 8080, User: "root", Home: "/home/app"}
+	if cfg != want {
+		t.Errorf("Parser.Parse() = %+v, want %+v", cfg, want)
+	}
+
+	wantKeys := []string{"MYAPP_PORT", "MYAPP_HTTP_PORT", "LEGACYAPP_PORT", "LEGACYAPP_HTTP_PORT"}
+	if got := p.envKeys(p.fields["Port"].tags); !reflect.DeepEqual(got, wantKeys) {
+		t.Errorf("Parser.envKeys() = %v, want %v", got, wantKeys)
+	}
+}
+
+func TestWithEnvPrefixes_emptyPrefix(t *testing.T) {
+	type testStruct struct {
+		Port int    `config:"name:port;desc:Port"`
+		Host string `config:"name:host;desc:Host"`
+	}
+
+	tests := []struct {
+		name     string
+		prefixes []string
+		want     testStruct
+		wantKeys []string
+	}{
+		{name: "legacy prefix", prefixes: []string{"LEGACY_"}, want: testStruct{Port: 80, Host: "old"}, wantKeys: []string{"PORT", "LEGACY_PORT"}},
+		{name: "empty prefix", prefixes: []string{""}, want: testStruct{Port: 80}, wantKeys: []string{"PORT"}},
+		{name: "repeated prefix", prefixes: []string{"", "legacy_", "LEGACY_"}, want: testStruct{Port: 80, Host: "old"}, wantKeys: []string{"PORT", "LEGACY_PORT"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = []string{"/app/test"}
+			t.Setenv("PORT", "80")
+			t.Setenv("LEGACY_PORT", "8080")
+			t.Setenv("LEGACY_HOST", "old")
+
+			var cfg testStruct
+			p, err := NewParser(&cfg, WithEnvPrefixes(tt.prefixes...), WithHelpSourceNames())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = p.Parse("", ""); err != nil {
+				t.Fatalf("Parser.Parse() error = %v", err)
+			}
+			if cfg != tt.want {
+				t.Errorf("Parser.Parse() = %+v, want %+v", cfg, tt.want)
+			}
+			if got := p.envKeys(p.fields["Port"].tags); !reflect.DeepEqual(got, tt.wantKeys) {
+				t.Errorf("Parser.envKeys() = %v, want %v", got, tt.wantKeys)
+			}
+			if help := p.Help(""); !strings.Contains(help, "env PORT") {
+				t.Errorf("Parser.Help() = %s, want env PORT", help)
+			}
+		})
+	}
+}
+
+func TestParser_envKey_nested(t *testing.T) {
+	tests := []struct {
+		name string
+		sep  string
+		want string
+	}{
+		{name: "default", want: "APP_DB_HOST"},
+		{name: "double underscore", sep: "__", want: "APP_DB__HOST"},
+		{name: "dot", sep: ".", want: "APP_DB.HOST"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{envPrefix: "app_"}
+			if tt.sep != "" {
+				WithEnvNestedSeparator(tt.sep)(p)
+			}
+			if got := p.envKey("db.host"); got != tt.want {
+				t.Errorf("Parser.envKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithEnvListSeparator(t *testing.T) {
+	type testStruct struct {
+		Path  []string `config:"name:path;noprefix"`
+		Hosts []string `config:"name:hosts;sep: "`
+		Tags  []string `config:"name:tags;mode:cli,env"`
+	}
+
+	os.Args = []string{"/app/test", "--tags=a,b"}
+	t.Setenv("PATH", "/usr/bin:/bin")
+	t.Setenv("HOSTS", "a  b c")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg, WithEnvListSeparator(':'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	want := testStruct{Path: []string{"/usr/bin", "/bin"}, Hosts: []string{"a", "b", "c"}, Tags: []string{"a", "b"}}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Parser.Parse() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestParser_Parse_indexedEnv(t *testing.T) {
+	type server struct {
+		Host string `config:"name:host"`
+		Port int    `config:"name:port"`
+	}
+	type testStruct struct {
+		Hosts   []string `config:"name:hosts"`
+		Ports   []int    `config:"name:ports"`
+		Servers []server `config:"name:servers"`
+		Names   []string `config:"name:names;mode:cli"`
+	}
+
+	os.Args = []string{"/app/test"}
+	t.Setenv("HOSTS_0", "a,b")
+	t.Setenv("HOSTS_1", "c")
+	t.Setenv("HOSTS_3", "zzz")
+	t.Setenv("PORTS", "80")
+	t.Setenv("PORTS_0", "8080")
+	t.Setenv("SERVERS_0", `{"host":"a","port":80}`)
+	t.Setenv("SERVERS_1", `{"host":"b","port":81}`)
+	t.Setenv("NAMES_0", "zzz")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	want := testStruct{
+		Hosts:   []string{"a,b", "c"},
+		Ports:   []int{80},
+		Servers: []server{{Host: "a", Port: 80}, {Host: "b", Port: 81}},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Parser.Parse() = %+v, want %+v", cfg, want)
+	}
+	if got := p.Snapshot().Values["hosts"]; got != "a,b,c" {
+		t.Errorf("Parser.Snapshot() hosts = %v", got)
+	}
+}
+
+func TestParser_Parse_envMap(t *testing.T) {
+	type testStruct struct {
+		Prefix string            `config:"name:prefix;mode:cli"`
+		Labels map[string]string `config:"name:labels"`
+		Limits map[string]int    `config:"name:limits"`
+		Tags   map[string]string `config:"name:tags"`
+	}
+
+	os.Args = []string{"/app/test", "--prefix=app_"}
+	t.Setenv("APP_LABELS_TEAM", "core")
+	t.Setenv("APP_LABELS_Cost_Center", "a,b=c")
+	t.Setenv("APP_LIMITS_CPU", "2")
+	t.Setenv("APP_TAGS", "env=prod")
+	t.Setenv("APP_TAGS_ZZZ", "zzz")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", "prefix"); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	want := testStruct{
+		Prefix: "app_",
+		Labels: map[string]string{"team": "core", "cost_center": "a,b=c"},
+		Limits: map[string]int{"cpu": 2},
+		Tags:   map[string]string{"env": "prod"},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Parser.Parse() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestParser_Parse_envMap_otherFields(t *testing.T) {
+	type testStruct struct {
+		DB      map[string]string `config:"name:db;mode:env"`
+		DBHost  string            `config:"name:db_host;mode:env"`
+		DBPeers []string          `config:"name:db_peers;mode:env"`
+		DBPass  string            `config:"name:db_pass;mode:env"`
+		Cli     string            `config:"name:db_cli;mode:cli"`
+	}
+
+	secret := filepath.Join(t.TempDir(), "pass")
+	if err := os.WriteFile(secret, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Args = []string{"/app/test"}
+	t.Setenv("DB_HOST", "localhost")
+	t.Setenv("DB_PEERS_0", "a:1,b:2")
+	t.Setenv("DB_PASS_FILE", secret)
+	t.Setenv("DB_CLI", "cli")
+	t.Setenv("DB_SSLMODE", "disable")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	want := testStruct{
+		DB:      map[string]string{"sslmode": "disable", "cli": "cli"},
+		DBHost:  "localhost",
+		DBPeers: []string{"a:1,b:2"},
+		DBPass:  "secret",
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Parser.Parse() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestWithEnvJSON(t *testing.T) {
+	type testStruct struct {
+		Config string `config:"name:config;mode:cli"`
+		Host   string `config:"name:host;mode:cfg"`
+		Port   int    `config:"name:port;mode:cfg,cli"`
+		DB     struct {
+			User string `config:"name:user"`
+		} `config:"name:db;mode:cfg"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"host":"file","port":80}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     string
+		want    testStruct
+		wantErr bool
+	}{
+		{name: "override file", env: `{"port":8080,"db":{"user":"root"}}`, want: testStruct{Host: "file", Port: 9090, DB: struct {
+			User string `config:"name:user"`
+		}{User: "root"}}},
+		{name: "empty", env: "", want: testStruct{Host: "file", Port: 9090}},
+		{name: "broken", env: `{"port":`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = []string{"/app/test", "--config=" + path, "--port=9090"}
+			t.Setenv("APP_CONFIG", tt.env)
+
+			var cfg testStruct
+			p, err := NewParser(&cfg, WithEnvJSON("APP_CONFIG"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.Parse("config", "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parser.Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			tt.want.Config = path
+			if !tt.wantErr && !reflect.DeepEqual(cfg, tt.want) {
+				t.Errorf("Parser.Parse() = %+v, want %+v", cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithLookupEnv(t *testing.T) {
+	type testStruct struct {
+		Host  string   `config:"name:host"`
+		Hosts []string `config:"name:hosts"`
+		Pass  string   `config:"name:pass"`
+	}
+
+	secret := filepath.Join(t.TempDir(), "pass")
+	if err := os.WriteFile(secret, []byte("qwerty"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Args = []string{"/app/test"}
+	t.Setenv("HOST", "zzz")
+	env := map[string]string{"HOST": "localhost", "HOSTS_0": "a", "HOSTS_1": "b", "PASS_FILE": secret}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg, WithLookupEnv(lookup))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	want := testStruct{Host: "localhost", Hosts: []string{"a", "b"}, Pass: "qwerty"}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Parser.Parse() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestWithEnvSnapshot(t *testing.T) {
+	type testStruct struct {
+		Host string `config:"name:host"`
+	}
+
+	os.Args = []string{"/app/test"}
+	t.Setenv("HOST", "first")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg, WithEnvSnapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	t.Setenv("HOST", "second")
+	p.mu.Lock()
+	_, err = p.refill()
+	p.mu.Unlock()
+	if err != nil {
+		t.Fatalf("Parser.refill() error = %v", err)
+	}
+	if cfg.Host != "first" {
+		t.Errorf("Parser.refill() host = %v, want %v", cfg.Host, "first")
+	}
+
+	if _, err = p.Reload(); err != nil {
+		t.Fatalf("Parser.Reload() error = %v", err)
+	}
+	if cfg.Host != "second" {
+		t.Errorf("Parser.Reload() host = %v, want %v", cfg.Host, "second")
+	}
+}
+
+func TestWithEnvCase(t *testing.T) {
+	type testStruct struct {
+		Host  string `config:"name:db.host"`
+		Token string `config:"name:token;env:apiToken;envcase:exact;noprefix"`
+		Level string `config:"name:level;envcase:upper"`
+	}
+
+	tests := []struct {
+		name    string
+		envCase EnvCase
+		env     map[string]string
+		want    testStruct
+	}{
+		{
+			name: "default",
+			env:  map[string]string{"APP_DB_HOST": "upper", "apiToken": "exact", "APP_LEVEL": "info"},
+			want: testStruct{Host: "upper", Token: "exact", Level: "info"},
+		},
+		{
+			name:    "lower",
+			envCase: EnvCaseLower,
+			env:     map[string]string{"app_db_host": "lower", "APP_DB_HOST": "upper", "apiToken": "exact", "APP_LEVEL": "info"},
+			want:    testStruct{Host: "lower", Token: "exact", Level: "info"},
+		},
+		{
+			name:    "exact",
+			envCase: EnvCaseExact,
+			env:     map[string]string{"App_db_host": "exact", "APP_LEVEL": "info"},
+			want:    testStruct{Host: "exact", Level: "info"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = []string{"/app/test"}
+			lookup := func(key string) (string, bool) {
+				value, ok := tt.env[key]
+				return value, ok
+			}
+
+			var cfg testStruct
+			p, err := NewParser(&cfg, WithEnvCase(tt.envCase), WithLookupEnv(lookup), WithEnvPrefixes("App_"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = p.Parse("", ""); err != nil {
+				t.Fatalf("Parser.Parse() error = %v", err)
+			}
+			if cfg != tt.want {
+				t.Errorf("Parser.Parse() = %+v, want %+v", cfg, tt.want)
+			}
+		})
+	}
+
+	var wrong struct {
+		Host string `config:"name:host;envcase:camel"`
+	}
+	if _, err := NewParser(&wrong); err == nil {
+		t.Errorf("NewParser() should fail with wrong envcase tag")
+	}
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Endpoint of local etcd, used if EtcdSource.Endpoints is empty
+const defaultEtcdEndpoint = "http://127.0.0.1:2379"
+
+// Source that reads all keys under prefix of etcd v3 (with its http/json gateway).
+// Key path parts are mapped into nested config names. Ex.: prefix "/app/" and key "/app/db/host" gives "db.host"
+type EtcdSource struct {
+	Endpoints []string     // Cluster members urls. Tried in order until one responds. Default is http://127.0.0.1:2379
+	Prefix    string       // Keys prefix. Ex.: /services/api/
+	Username  string       // Auth user. Auth is skipped if empty
+	Password  string       // Auth password
+	Client    *http.Client // Custom http client. Default is http.DefaultClient
+}
+
+// Single key-value of etcd range response. Both fields are base64 encoded
+type etcdKV struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func (s *EtcdSource) Name() string {
+	return "etcd"
+}
+
+// Read all keys under prefix with range request
+func (s *EtcdSource) Load(ctx context.Context) (map[string]string, error) {
+	key, rangeEnd := etcdPrefixRange(s.Prefix)
+
+	var resp struct {
+		Kvs []etcdKV `json:"kvs"`
+	}
+	err := s.call(ctx, "/v3/kv/range", map[string]string{"key": key, "range_end": rangeEnd}, func(r *http.Response) error {
+		return json.NewDecoder(r.Body).Decode(&resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	for _, kv := range resp.Kvs {
+		rawKey, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		name := keyFromPath(string(rawKey), s.Prefix)
+		if name == "" {
+			continue
+		}
+		result[name] = string(value)
+	}
+
+	return result, nil
+}
+
+// Watch for changes of keys under prefix. Block until ctx is done or watch stream is broken
+func (s *EtcdSource) Watch(ctx context.Context, onChange func()) error {
+	key, rangeEnd := etcdPrefixRange(s.Prefix)
+	body := map[string]interface{}{
+		"create_request": map[string]string{"key": key, "range_end": rangeEnd},
+	}
+
+	err := s.call(ctx, "/v3/watch", body, func(r *http.Response) error {
+		decoder := json.NewDecoder(r.Body)
+		for {
+			var msg struct {
+				Result struct {
+					Events []json.RawMessage `json:"events"`
+				} `json:"result"`
+			}
+			err := decoder.Decode(&msg)
+			if err != nil {
+				return err
+			}
+			if len(msg.Result.Events) > 0 {
+				onChange()
+			}
+		}
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return err
+}
+
+// Send request to first available endpoint and handle its response
+func (s *EtcdSource) call(ctx context.Context, path string, body interface{}, handle func(r *http.Response) error) error {
+	endpoints := s.Endpoints
+	if len(endpoints) == 0 {
+		endpoints = []string{defaultEtcdEndpoint}
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, endpoint := range endpoints {
+		endpoint = strings.TrimRight(endpoint, "/")
+
+		token, err := s.authenticate(ctx, client, endpoint)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", token)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			lastErr = errors.New(fmt.Sprintf("etcd %s responded with %s", endpoint, resp.Status))
+			continue
+		}
+
+		err = handle(resp)
+		resp.Body.Close()
+		return err
+	}
+
+	return lastErr
+}
+
+// Get auth token for endpoint. Return empty token if auth is not configured
+func (s *EtcdSource) authenticate(ctx context.Context, client *http.Client, endpoint string) (string, error) {
+	if s.Username == "" {
+		return "", nil
+	}
+
+	payload, err := json.Marshal(map[string]string{"name": s.Username, "password": s.Password})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/v3/auth/authenticate", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New(fmt.Sprintf("etcd %s authentication failed with %s", endpoint, resp.Status))
+	}
+
+	var auth struct {
+		Token string `json:"token"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&auth)
+	if err != nil {
+		return "", err
+	}
+
+	return auth.Token, nil
+}
+
+// Return base64 encoded key and range end that cover all keys with prefix
+func etcdPrefixRange(prefix string) (string, string) {
+	if prefix == "" {
+		// "\x00" for both key and range end means all keys
+		zero := base64.StdEncoding.EncodeToString([]byte{0})
+		return zero, zero
+	}
+
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			end = end[:i+1]
+			return base64.StdEncoding.EncodeToString([]byte(prefix)), base64.StdEncoding.EncodeToString(end)
+		}
+	}
+
+	// Prefix consists of 0xff bytes only, so range is not limited
+	return base64.StdEncoding.EncodeToString([]byte(prefix)), base64.StdEncoding.EncodeToString([]byte{0})
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEtcdSource_Load(t *testing.T) {
+	b64 := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/auth/authenticate":
+			var auth map[string]string
+			json.NewDecoder(r.Body).Decode(&auth)
+			if auth["name"] != "root" || auth["password"] != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"token":"secret_token"}`))
+		case "/v3/kv/range":
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			switch req["key"] {
+			case b64("/app/"):
+				if req["range_end"] != b64("/app0") {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.Write([]byte(`{"kvs":[{"key":"` + b64("/app/port") + `","value":"` + b64("8080") + `"},{"key":"` + b64("/app/db/host") + `","value":"` + b64("localhost") + `"}]}`))
+			case b64("/secret/"):
+				if r.Header.Get("Authorization") != "secret_token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Write([]byte(`{"kvs":[{"key":"` + b64("/secret/pass") + `","value":"` + b64("qwerty") + `"}]}`))
+			default:
+				w.Write([]byte(`{}`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		source  EtcdSource
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "prefix", source: EtcdSource{Endpoints: []string{server.URL}, Prefix: "/app/"}, want: map[string]string{"port": "8080", "db.host": "localhost"}},
+		{name: "failover", source: EtcdSource{Endpoints: []string{"http://127.0.0.1:1", server.URL}, Prefix: "/app/"}, want: map[string]string{"port": "8080", "db.host": "localhost"}},
+		{name: "auth", source: EtcdSource{Endpoints: []string{server.URL}, Prefix: "/secret/", Username: "root", Password: "pass"}, want: map[string]string{"pass": "qwerty"}},
+		{name: "wrong auth", source: EtcdSource{Endpoints: []string{server.URL}, Prefix: "/secret/", Username: "root", Password: "zzz"}, wantErr: true},
+		{name: "no auth", source: EtcdSource{Endpoints: []string{server.URL}, Prefix: "/secret/"}, wantErr: true},
+		{name: "empty", source: EtcdSource{Endpoints: []string{server.URL}, Prefix: "/zzz/"}, want: map[string]string{}},
+		{name: "unavailable", source: EtcdSource{Endpoints: []string{"http://127.0.0.1:1"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.source.Load(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EtcdSource.Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EtcdSource.Load() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEtcdSource_Watch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":{"created":true}}`))
+		w.Write([]byte(`{"result":{"events":[{"type":"PUT"}]}}`))
+		w.Write([]byte(`{"result":{"events":[{"type":"DELETE"}]}}`))
+	}))
+	defer server.Close()
+
+	changes := 0
+	source := EtcdSource{Endpoints: []string{server.URL}, Prefix: "/app/"}
+	err := source.Watch(context.Background(), func() { changes++ })
+	if err == nil {
+		t.Errorf("EtcdSource.Watch() should return error on closed stream")
+	}
+	if changes != 2 {
+		t.Errorf("EtcdSource.Watch() changes = %v, want %v", changes, 2)
+	}
+}
+
+func Test_etcdPrefixRange(t *testing.T) {
+	b64 := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name      string
+		prefix    string
+		wantKey   string
+		wantRange string
+	}{
+		{name: "empty", prefix: "", wantKey: b64("\x00"), wantRange: b64("\x00")},
+		{name: "simple", prefix: "/app/", wantKey: b64("/app/"), wantRange: b64("/app0")},
+		{name: "overflow", prefix: "a\xff", wantKey: b64("a\xff"), wantRange: b64("b")},
+		{name: "max", prefix: "\xff", wantKey: b64("\xff"), wantRange: b64("\x00")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotKey, gotRange := etcdPrefixRange(tt.prefix)
+			if gotKey != tt.wantKey || gotRange != tt.wantRange {
+				t.Errorf("etcdPrefixRange() = %v, %v, want %v, %v", gotKey, gotRange, tt.wantKey, tt.wantRange)
+			}
+		})
+	}
+}
+
+func TestEtcdSource_watchedByParser(t *testing.T) {
+	type testStruct struct {
+		Port int `config:"name:port;mode:cfg"`
+	}
+
+	b64 := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	var port atomic.Value
+	port.Store("8080")
+	pushes := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/kv/range":
+			w.Write([]byte(`{"kvs":[{"key":"` + b64("/app/port") + `","value":"` + b64(port.Load().(string)) + `"}]}`))
+		case "/v3/watch":
+			w.Write([]byte(`{"result":{"created":true}}`))
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case <-r.Context().Done():
+					return
+				case <-pushes:
+					w.Write([]byte(`{"result":{"events":[{"type":"PUT"}]}}`))
+					w.(http.Flusher).Flush()
+				}
+			}
+		}
+	}))
+	defer server.Close()
+
+	os.Args = []string{"/app/test"}
+	changes := make(chan struct{}, 10)
+
+	var cfg testStruct
+	p, err := NewParser(&cfg,
+		WithSource(&EtcdSource{Endpoints: []string{server.URL}, Prefix: "/app/"}),
+		WithWatch(),
+		WithOnChange(func() { changes <- struct{}{} }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	port.Store("9090")
+	pushes <- struct{}{}
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatalf("Pushed change is not applied")
+	}
+	p.mu.Lock()
+	if cfg.Port != 9090 {
+		t.Errorf("Watched config = %v, want port 9090", cfg)
+	}
+	p.mu.Unlock()
+}
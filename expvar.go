@@ -0,0 +1,13 @@
+package config
+
+import (
+	"expvar"
+)
+
+// Publish effective configuration (with redacted secrets) as expvar variable, so it is available under /debug/vars.
+// Value is read on each request, so it follows reloads. Like expvar.Publish, panics if name is already used
+func (p *Parser) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return p.redactedValues()
+	}))
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParser_PublishExpvar(t *testing.T) {
+	type testStruct struct {
+		Port   int    `config:"name:port;mode:env"`
+		DbPass string `config:"name:db_pass;mode:env;secret:true"`
+	}
+
+	os.Args = []string{"/app/test"}
+	t.Setenv("PORT", "8080")
+	t.Setenv("DB_PASS", "qwerty")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	name := fmt.Sprintf("config_test_%d", time.Now().UnixNano()) // Names can't be reused, ex.: by go test -count=2
+	p.PublishExpvar(name)
+
+	got := map[string]string{}
+	if err = json.Unmarshal([]byte(expvar.Get(name).String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"port": "8080", "db_pass": "****"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PublishExpvar() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Save last successfully loaded values of source with specific name into file,
+// and use them if source is unavailable. Usage of cached values is reported to warning handler
+func WithSourceCacheFile(name, path string) Option {
+	return func(p *Parser) {
+		if p.sourceCaches == nil {
+			p.sourceCaches = make(map[string]string)
+		}
+		p.sourceCaches[name] = path
+	}
+}
+
+// Load values of source, falling back to cache file if it is set for the source. Return warning if cached values are used
+func (p *Parser) loadSourceWithFallback(ctx context.Context, src Source) (values map[string]string, warning error, err error) {
+	path, ok := p.sourceCaches[src.Name()]
+	values, err = p.loadSourceWithRetry(ctx, src)
+	if !ok {
+		return values, nil, err
+	}
+
+	if err == nil {
+		if cacheErr := writeCacheFile(path, values); cacheErr != nil {
+			return values, fmt.Errorf("%s: cannot save cache file: %w", src.Name(), cacheErr), nil
+		}
+		return values, nil, nil
+	}
+
+	cached, cacheErr := readCacheFile(path)
+	if cacheErr != nil {
+		return nil, nil, err
+	}
+
+	return cached, fmt.Errorf("%s: cached values from %s are used: %w", src.Name(), path, err), nil
+}
+
+// Read values saved by writeCacheFile
+func readCacheFile(path string) (map[string]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	err = json.Unmarshal(content, &values)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// Save values as json. File is replaced atomically, so broken cache can't be left on crash
+func writeCacheFile(path string, values map[string]string) error {
+	content, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, content, 0600) // Cache can contain secrets
+}
+
+// Write content into temporary file with permissions, and then replace file at path with it
+func writeFileAtomic(path string, content []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(content)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmp.Name(), perm)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
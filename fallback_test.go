@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParser_loadSourceWithFallback(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "consul.json")
+
+	p := &Parser{}
+	WithSourceCacheFile("consul", cache)(p)
+
+	// Source is available, so values are saved
+	values, warning, err := p.loadSourceWithFallback(context.Background(), &staticSource{name: "consul", values: map[string]string{"a": "1"}})
+	if err != nil || warning != nil {
+		t.Fatalf("Parser.loadSourceWithFallback() warning = %v, error = %v", warning, err)
+	}
+	if want := map[string]string{"a": "1"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("Parser.loadSourceWithFallback() = %v, want %v", values, want)
+	}
+	if info, err := os.Stat(cache); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Cache file is not saved properly: %v, %v", info, err)
+	}
+
+	// Source is unavailable, so cached values are used
+	values, warning, err = p.loadSourceWithFallback(context.Background(), &staticSource{name: "consul", err: errors.New("unavailable")})
+	if err != nil || warning == nil {
+		t.Fatalf("Parser.loadSourceWithFallback() warning = %v, error = %v", warning, err)
+	}
+	if want := map[string]string{"a": "1"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("Parser.loadSourceWithFallback() = %v, want %v", values, want)
+	}
+
+	// No cache for source
+	_, _, err = p.loadSourceWithFallback(context.Background(), &staticSource{name: "etcd", err: errors.New("unavailable")})
+	if err == nil {
+		t.Errorf("Parser.loadSourceWithFallback() should fail without cache")
+	}
+
+	// Cache file is missing
+	p = &Parser{}
+	WithSourceCacheFile("consul", filepath.Join(dir, "zzz.json"))(p)
+	_, _, err = p.loadSourceWithFallback(context.Background(), &staticSource{name: "consul", err: errors.New("unavailable")})
+	if err == nil {
+		t.Errorf("Parser.loadSourceWithFallback() should fail without cache file")
+	}
+}
+
+func TestParser_loadSourcesWarnings(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "consul.json")
+	if err := writeCacheFile(cache, map[string]string{"a": "cached"}); err != nil {
+		t.Fatal(err)
+	}
+
+	warnings := []error{}
+	p := &Parser{}
+	WithWarningHandler(func(err error) { warnings = append(warnings, err) })(p)
+	WithSourceCacheFile("consul", cache)(p)
+	WithSource(&staticSource{name: "consul", err: errors.New("unavailable")})(p)
+
+	if err := p.loadSources(); err != nil {
+		t.Fatalf("Parser.loadSources() error = %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("Parser.loadSources() warnings = %v, want 1", warnings)
+	}
+	if p.parsedCfg["a"] != "cached" {
+		t.Errorf("Parser.loadSources() = %v", p.parsedCfg)
+	}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Metadata of config field, for tools that introspect options (web UIs, doc generators, admission controllers).
+// Names are empty for sources that are not allowed for field
+type FieldInfo struct {
+	Name            string   // Config name
+	Path            string   // Path of struct field. Ex.: DB.Host
+	Flag            string   // Command-line flag. Ex.: "--db.host"
+	Short           string   // Short command-line flag. Ex.: "-d"
+	Env             string   // Env variable, with env prefix of last Parse
+	Key             string   // Config file key
+	Aliases         []string // Former names
+	Type            string   // Go type. Ex.: "time.Duration"
+	Default         string   // Secret default is redacted
+	HasDefault      bool
+	Example         string // Value from example tag
+	Description     string
+	LongDescription string   // Detailed description from longdesc tag
+	Modes           []string // Allowed sources: cli, cfg, env
+	OneOf           []string // Allowed values
+	Validators      []string // Names from validate tag
+	Required        bool
+	Secret          bool
+	Deprecated      bool
+	Hidden          bool
+}
+
+// Return metadata of all config fields (including hidden ones), sorted by config name (or in declaration order)
+func (p *Parser) Fields() []FieldInfo {
+	fields := p.sortedFields()
+	result := make([]FieldInfo, len(fields))
+	for i, field := range fields {
+		result[i] = p.fieldInfo(field)
+	}
+
+	return result
+}
+
+// Collect metadata of field
+func (p *Parser) fieldInfo(field *structField) FieldInfo {
+	tags := field.tags
+	info := FieldInfo{
+		Name:            tags.name,
+		Path:            field.name,
+		Aliases:         append([]string{}, tags.aliases...),
+		Default:         shownDefault(tags),
+		HasDefault:      tags.hasDefaultValue,
+		Example:         tags.example,
+		Description:     tags.description,
+		LongDescription: tags.longDescription,
+		Modes:           []string{},
+		OneOf:           append([]string{}, tags.oneOf...),
+		Validators:      append([]string{}, tags.validators...),
+		Required:        tags.required,
+		Secret:          tags.secret,
+		Deprecated:      tags.isDeprecated,
+		Hidden:          tags.hidden,
+	}
+	if !tags.hasDefaultValue {
+		info.Default = ""
+	}
+	if t := p.fieldType(field); t != nil {
+		info.Type = t.String()
+	}
+
+	for _, mode := range modesOrder {
+		if tags.mode != 0 && tags.mode&modes[mode] == 0 {
+			continue
+		}
+		info.Modes = append(info.Modes, mode)
+		switch modes[mode] {
+		case modeCli:
+			info.Flag = "--" + tags.cliName()
+			if tags.short != "" {
+				info.Short = "-" + tags.short
+			}
+		case modeCfg:
+			info.Key = tags.cfgName()
+		case modeEnv:
+			info.Env = p.envKeys(tags)[0]
+		}
+	}
+
+	return info
+}
+
+// Return fields sorted by config name, or in declaration order if WithDeclarationOrder is set
+func (p *Parser) sortedFields() []*structField {
+	fields := []*structField{}
+	for _, field := range p.fields {
+		fields = append(fields, field)
+	}
+
+	order := map[string]int{}
+	if p.declarationOrder {
+		order = p.fieldsOrder()
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		a, aOk := order[fields[i].name]
+		b, bOk := order[fields[j].name]
+		if aOk && bOk {
+			return a < b
+		}
+
+		return fields[i].tags.name < fields[j].tags.name
+	})
+
+	return fields
+}
+
+// List fields in struct declaration order (nested fields are in place of their struct), instead of sorting them
+// by config name, in help, docs and Fields
+func WithDeclarationOrder() Option {
+	return func(p *Parser) {
+		p.declarationOrder = true
+	}
+}
+
+// Return positions of struct fields in declaration order. Keys - paths of fields, like names of structField
+func (p *Parser) fieldsOrder() map[string]int {
+	order := make(map[string]int)
+	if p.in == nil {
+		return order
+	}
+
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return
+		}
+		for i := 0; i < t.NumField(); i++ {
+			path := t.Field(i).Name
+			if prefix != "" {
+				path = fmt.Sprintf("%s%s%s", prefix, separatorNested, path)
+			}
+			order[path] = len(order)
+			if p.hasFieldsUnder(path) { // Just structs with config fields are walked, so recursive types don't loop
+				walk(t.Field(i).Type, path)
+			}
+		}
+	}
+	walk(reflect.TypeOf(p.in).Elem(), "")
+
+	return order
+}
+
+// Check if there are config fields inside of struct field with path
+func (p *Parser) hasFieldsUnder(path string) bool {
+	for name := range p.fields {
+		if strings.HasPrefix(name, path+separatorNested) {
+			return true
+		}
+	}
+
+	return false
+}
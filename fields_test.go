@@ -0,0 +1,83 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParser_Fields(t *testing.T) {
+	type testStruct struct {
+		DB struct {
+			Host string `config:"name:host;short:d;alias:hostname;default:localhost;desc:Database host;required"`
+		} `config:"name:db"`
+		Timeout  time.Duration `config:"name:timeout;mode:env;validate:positive"`
+		Password string        `config:"name:password;mode:cfg,env;secret;default:qwerty;hidden"`
+		Level    string        `config:"name:level;mode:cli;oneof:debug,info;deprecated"`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.envPrefix = "APP_"
+
+	want := []FieldInfo{
+		{
+			Name: "db.host", Path: "DB.Host", Flag: "--db.host", Short: "-d", Env: "APP_DB_HOST", Key: "db.host",
+			Aliases: []string{"db.hostname"}, Type: "string", Default: "localhost", HasDefault: true, Description: "Database host",
+			Modes: []string{"cli", "cfg", "env"}, OneOf: []string{}, Validators: []string{}, Required: true,
+		},
+		{
+			Name: "level", Path: "Level", Flag: "--level", Aliases: []string{}, Type: "string", Modes: []string{"cli"},
+			OneOf: []string{"debug", "info"}, Validators: []string{}, Deprecated: true,
+		},
+		{
+			Name: "password", Path: "Password", Env: "APP_PASSWORD", Key: "password", Aliases: []string{}, Type: "string",
+			Default: redacted, HasDefault: true, Modes: []string{"cfg", "env"}, OneOf: []string{}, Validators: []string{},
+			Secret: true, Hidden: true,
+		},
+		{
+			Name: "timeout", Path: "Timeout", Env: "APP_TIMEOUT", Aliases: []string{}, Type: "time.Duration",
+			Modes: []string{"env"}, OneOf: []string{}, Validators: []string{"positive"},
+		},
+	}
+	if got := p.Fields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parser.Fields() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWithDeclarationOrder(t *testing.T) {
+	type node struct {
+		Next *node
+	}
+	type testStruct struct {
+		Port int `config:"name:port;desc:Port"`
+		DB   struct {
+			User string `config:"name:user;desc:User"`
+			Host string `config:"name:host;desc:Host"`
+		} `config:"name:db;mode:cli"`
+		List  *node
+		Level string `config:"name:level;mode:cli;desc:Level"`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg, WithDeclarationOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "--port    Port\n--db.user User (cli only)\n--db.host Host (cli only)\n--level   Level (cli only)\n"
+	if got := p.Help(""); got != want {
+		t.Errorf("Parser.Help() = %q, want %q", got, want)
+	}
+
+	names := []string{}
+	for _, field := range p.Fields() {
+		names = append(names, field.Name)
+	}
+	if want := []string{"port", "db.user", "db.host", "level"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Parser.Fields() names = %v, want %v", names, want)
+	}
+}
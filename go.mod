@@ -2,4 +2,10 @@ module github.com/zamaldinov28/config
 
 go 1.18
 
-require golang.org/x/exp v0.0.0-20220602145555-4a0574d9293f
+require (
+	golang.org/x/exp v0.0.0-20220602145555-4a0574d9293f
+	golang.org/x/sync v0.1.0
+	golang.org/x/sys v0.0.0-20220114195835-da31bd327af9
+)
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Replacement of secret values
+const redacted = "****"
+
+// Return http handler that renders effective configuration (values written into config struct) as JSON object,
+// where keys are config names. Values of fields with secret tag (including fields of items of slices of structs
+// and of json values) are redacted. Useful for debug/admin ports
+func Handler(p *Parser) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, err := json.MarshalIndent(p.redactedValues(), "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(content)
+	})
+}
+
+// Return copy of effective values with redacted secrets
+func (p *Parser) redactedValues() map[string]string {
+	if p.mu != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+	}
+
+	result := copyValues(p.values)
+	for _, field := range p.fields {
+		if value, ok := result[field.tags.name]; ok {
+			result[field.tags.name] = p.shownValue(field, value)
+		}
+	}
+
+	return result
+}
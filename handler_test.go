@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	type testStruct struct {
+		Port   int    `config:"name:port;mode:env"`
+		DbPass string `config:"name:db_pass;mode:env;secret:true"`
+		DbUser string `config:"name:db_user;mode:env;default:root;secret:false"`
+		Token  string `config:"name:token;mode:env;secret"`
+	}
+
+	os.Args = []string{"/app/test"}
+	t.Setenv("PORT", "8080")
+	t.Setenv("DB_PASS", "qwerty")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler(&p).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Handler() status = %v, content type = %v", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	got := map[string]string{}
+	if err = json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"port": "8080", "db_pass": "****", "db_user": "root"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Handler() = %v, want %v", got, want)
+	}
+}
+
+func Test_parseBoolTag(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{value: "", want: true},
+		{value: "true", want: true},
+		{value: "Y", want: true},
+		{value: "false", want: false},
+		{value: "no", want: false},
+		{value: "zzz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseBoolTag("secret", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseBoolTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseBoolTag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_nestedSecrets(t *testing.T) {
+	type server struct {
+		Host     string `config:"name:host"`
+		Password string `config:"name:password;secret"`
+	}
+	type auth struct {
+		User  string `json:"user"`
+		Token string `json:"token" config:"secret"`
+	}
+	type testStruct struct {
+		Servers []server `config:"name:servers;mode:env"`
+		Auth    auth     `config:"name:auth;mode:env;encoding:json"`
+		Hosts   []server `config:"name:hosts;mode:env"`
+	}
+
+	os.Args = []string{"/app/test"}
+	t.Setenv("SERVERS", `[{"host": "db", "password": "qwerty"}]`)
+	t.Setenv("AUTH", `{"user": "admin", "token": "abc"}`)
+	t.Setenv("HOSTS", `[{"host": "cache"}]`)
+
+	var traces [][]FieldTrace
+	var cfg testStruct
+	p, err := NewParser(&cfg, WithTrace(func(trace []FieldTrace) {
+		traces = append(traces, trace)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"servers": `[{"host":"db","password":"****"}]`,
+		"auth":    `{"token":"****","user":"admin"}`,
+		"hosts":   `[{"host": "cache"}]`,
+	}
+
+	rec := httptest.NewRecorder()
+	Handler(&p).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	got := map[string]string{}
+	if err = json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Handler() = %v, want %v", got, want)
+	}
+
+	if got := p.Snapshot().Values; !reflect.DeepEqual(got, want) {
+		t.Errorf("Parser.Snapshot() = %v, want %v", got, want)
+	}
+
+	help := p.HelpResolved("")
+	for _, secret := range []string{"qwerty", "abc"} {
+		if strings.Contains(help, secret) {
+			t.Errorf("Parser.HelpResolved() = %s, contains secret %s", help, secret)
+		}
+	}
+	if !strings.Contains(help, `[{"host":"db","password":"****"}] (env)`) {
+		t.Errorf("Parser.HelpResolved() = %s, want redacted servers", help)
+	}
+
+	for _, trace := range traces[0] {
+		if content := fmt.Sprintf("%+v", trace); strings.Contains(content, "qwerty") || strings.Contains(content, "abc") {
+			t.Errorf("WithTrace() trace = %s, contains secret", content)
+		}
+	}
+
+	t.Setenv("SERVERS", `[{"host": "db", "password": "changed"}]`)
+	before := p.Snapshot()
+	if _, err = p.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if changes := Diff(before, p.Snapshot()); len(changes) != 1 || changes[0].Name != "servers" {
+		t.Errorf("Diff() = %v, want change of servers", changes)
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// Return stable hash of effective configuration (values written into config struct by last Parse or Reload).
+// Same values give same hash regardless of their sources, so it can be used to compare config generations of instances.
+// Secret values (including secret fields inside of values) are not hashed, because hash is published (ex.: as metric)
+// and low-entropy secrets could be brute-forced with it. So change of secret doesn't change hash
+func (p *Parser) Hash() string {
+	if p.mu != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+	}
+
+	values := copyValues(p.values)
+	for _, field := range p.fields {
+		if value, ok := values[field.tags.name]; ok {
+			values[field.tags.name] = p.shownValue(field, value)
+		}
+	}
+
+	return hashValues(values)
+}
+
+// Hash sorted name-value pairs. Lengths are included, so different pairs can't give same input
+func hashValues(values map[string]string) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%d:%s=%d:%s\n", len(name), name, len(values[name]), values[name])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParser_Hash(t *testing.T) {
+	type testStruct struct {
+		Port int    `config:"name:port;mode:env"`
+		Host string `config:"name:host;mode:env;default:localhost"`
+	}
+
+	os.Args = []string{"/app/test"}
+	t.Setenv("PORT", "8080")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty := p.Hash()
+
+	if err = p.Parse("", ""); err != nil {
+		t.Fatal(err)
+	}
+	first := p.Hash()
+	if first == empty || len(first) != 64 {
+		t.Errorf("Parser.Hash() = %v", first)
+	}
+
+	// Same values from other source give same hash
+	var other testStruct
+	p2, _ := NewParser(&other)
+	os.Args = []string{"/app/test", "--port=8080"}
+	t.Setenv("PORT", "")
+	os.Unsetenv("PORT")
+	p2.fields["Port"].tags.mode = modeCli
+	if err = p2.Parse("", ""); err != nil {
+		t.Fatal(err)
+	}
+	if p2.Hash() != first {
+		t.Errorf("Parser.Hash() = %v, want %v", p2.Hash(), first)
+	}
+
+	t.Setenv("PORT", "9090")
+	if _, err = p.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Hash() == first {
+		t.Errorf("Parser.Hash() is not changed after reload")
+	}
+}
+
+func Test_hashValues(t *testing.T) {
+	if hashValues(map[string]string{"a": "b=c"}) == hashValues(map[string]string{"a=b": "c"}) {
+		t.Errorf("hashValues() should differ for different pairs")
+	}
+	if hashValues(map[string]string{"a": "1", "b": "2"}) != hashValues(map[string]string{"b": "2", "a": "1"}) {
+		t.Errorf("hashValues() should not depend on order")
+	}
+}
+
+func TestParser_Hash_secrets(t *testing.T) {
+	type server struct {
+		Host     string `config:"name:host"`
+		Password string `config:"name:password;secret"`
+	}
+	type testStruct struct {
+		Port     int      `config:"name:port;mode:env"`
+		Password string   `config:"name:password;mode:env;secret"`
+		Servers  []server `config:"name:servers;mode:env"`
+	}
+
+	os.Args = []string{"/app/test"}
+	t.Setenv("PORT", "8080")
+
+	hashes := []string{}
+	for _, password := range []string{"1234", "qwerty"} {
+		t.Setenv("PASSWORD", password)
+		t.Setenv("SERVERS", `[{"host": "db", "password": "`+password+`"}]`)
+
+		var cfg testStruct
+		p, err := NewParser(&cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = p.Parse("", ""); err != nil {
+			t.Fatal(err)
+		}
+		if p.Hash() == hashValues(p.values) {
+			t.Errorf("Parser.Hash() covers raw secret values")
+		}
+		hashes = append(hashes, p.Hash())
+	}
+
+	if hashes[0] != hashes[1] {
+		t.Errorf("Parser.Hash() = %v, depends on secret values", hashes)
+	}
+}
@@ -0,0 +1,373 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Returned by Parse if auto help is enabled and --help or -h is passed. Help is already printed
+var ErrHelpRequested = errors.New("Help requested")
+
+// Cli names that request auto help
+const (
+	helpName      = "help"
+	helpShortName = "h"
+	helpFullValue = "full" // Value of help flag that requests long descriptions. Ex.: --help=full
+)
+
+// Descriptions are not wrapped into narrower column, because it would be less readable than long lines
+const minWrapWidth = 20
+
+// Template of usage text, used if WithHelpTemplate is not set
+var defaultHelpTemplate = template.Must(template.New("help").Parse("Usage: {{.Program}} [options]\n\nOptions:\n{{.Options}}"))
+
+// Data of help template
+type HelpData struct {
+	Program string      // Base name of executable
+	Options string      // Options in default two-column format, wrapped and colored like by WriteHelp
+	Entries []HelpEntry // Options one by one, in order of help, for own layout
+	Full    bool        // Full help is requested (--help=full), so Options contain long descriptions
+}
+
+// Single option of help
+type HelpEntry struct {
+	Name        string // Config name
+	Flag        string // Left column. Ex.: "-p, --port[=80]"
+	Description string // Right column: description with hints of allowed values, deprecation, example and sources
+
+	LongDescription string // Detailed description from longdesc tag
+}
+
+// Make Parse handle --help and -h: print usage line and help into writer (os.Stdout if nil) and return ErrHelpRequested.
+// --help=full prints long descriptions too. Names used by fields (as name, alias or short name) are left to them
+// and don't request help
+func WithAutoHelp(w io.Writer) Option {
+	return func(p *Parser) {
+		p.autoHelp = true
+		p.helpWriter = w
+	}
+}
+
+// Set template of usage text, printed by auto help and WriteUsage. Template gets HelpData, so it can add
+// synopsis, header and footer, or lay options out by itself. Ex.:
+// template.Must(template.New("help").Parse("{{.Program}} - my tool\n\n{{.Options}}\nReport bugs to ..."))
+func WithHelpTemplate(tmpl *template.Template) Option {
+	return func(p *Parser) {
+		p.helpTemplate = tmpl
+	}
+}
+
+// Make help list config file key and env variable (with env prefix) of each option, instead of just limited modes.
+// Ex.: "(cli, cfg db.host, env APP_DB_HOST)"
+func WithHelpSourceNames() Option {
+	return func(p *Parser) {
+		p.helpSourceNames = true
+	}
+}
+
+// Write usage text made with help template into writer
+func (p *Parser) WriteUsage(w io.Writer) error {
+	return p.writeUsage(w, false)
+}
+
+// Write usage text made with help template into writer. Long descriptions are added to options if isFull is set
+func (p *Parser) writeUsage(w io.Writer, isFull bool) error {
+	tmpl := p.helpTemplate
+	if tmpl == nil {
+		tmpl = defaultHelpTemplate
+	}
+
+	program := "app"
+	if len(os.Args) > 0 {
+		program = filepath.Base(os.Args[0])
+	}
+
+	return tmpl.Execute(w, HelpData{
+		Program: program,
+		Options: p.formatHelp("    ", writerWidth(w), p.useColor(w), isFull),
+		Entries: p.helpEntries(),
+		Full:    isFull,
+	})
+}
+
+// Return help like Help, but with descriptions wrapped to width of lines (width 0 means no wrapping).
+// Wrapped lines are indented to description column
+func (p *Parser) HelpWrapped(prefix string, width int) string {
+	return p.formatHelp(prefix, width, false, false)
+}
+
+// Return help like Help, but with long descriptions (longdesc tag) under options
+func (p *Parser) HelpFull(prefix string) string {
+	return p.formatHelp(prefix, 0, false, true)
+}
+
+// Write help into writer. If writer is terminal, descriptions are wrapped to its width.
+// Otherwise they are wrapped to width set by COLUMNS env variable, if it is set. Flags are colored according to WithColor
+func (p *Parser) WriteHelp(w io.Writer, prefix string) error {
+	_, err := io.WriteString(w, p.formatHelp(prefix, writerWidth(w), p.useColor(w), false))
+	return err
+}
+
+// Format help with descriptions wrapped to width (0 means no wrapping), with colored flags if isColored is set,
+// and with long descriptions if isFull is set
+func (p *Parser) formatHelp(prefix string, width int, isColored, isFull bool) string {
+	entries := p.helpEntries()
+
+	longestParameter := 0
+	for _, entry := range entries {
+		if len(entry.Flag) > longestParameter {
+			longestParameter = len(entry.Flag)
+		}
+	}
+
+	indent := len(prefix) + longestParameter + 1
+	buffer := bytes.NewBufferString("")
+	for _, entry := range entries {
+		flag := entry.Flag
+		if isColored {
+			flag = colorFlag(flag)
+		}
+		lines := wrapText(entry.Description, width-indent)
+		buffer.WriteString(fmt.Sprintf("%s%s%*s %s\n", prefix, flag, longestParameter-len(entry.Flag), "", lines[0]))
+		for _, line := range lines[1:] {
+			buffer.WriteString(fmt.Sprintf("%*s%s\n", indent, "", line))
+		}
+		if isFull && entry.LongDescription != "" {
+			for _, line := range wrapText(entry.LongDescription, width-indent) {
+				buffer.WriteString(fmt.Sprintf("%*s%s\n", indent, "", line))
+			}
+		}
+	}
+
+	return buffer.String()
+}
+
+// Return help with effective values of options and their sources (cli, cfg, env, default or name of external source),
+// as set by last Parse or Reload. Secret values are redacted. All options except hidden ones are listed
+func (p *Parser) HelpResolved(prefix string) string {
+	snapshot := p.Snapshot()
+
+	lines := [][2]string{}
+	longestParameter := 0
+	for _, field := range p.sortedFields() {
+		if field.tags.hidden {
+			continue
+		}
+
+		leftPart := fmt.Sprintf("--%s", field.tags.cliName())
+		rightPart := "(not set)"
+		if value, ok := snapshot.Values[field.tags.name]; ok {
+			rightPart = fmt.Sprintf("%s (%s)", value, snapshot.Sources[field.tags.name])
+		}
+		lines = append(lines, [2]string{leftPart, rightPart})
+
+		if len(leftPart) > longestParameter {
+			longestParameter = len(leftPart)
+		}
+	}
+
+	buffer := bytes.NewBufferString("")
+	for _, line := range lines {
+		buffer.WriteString(fmt.Sprintf("%s%-*s %s\n", prefix, longestParameter, line[0], line[1]))
+	}
+
+	return buffer.String()
+}
+
+// Return width of terminal of writer, or value of COLUMNS env variable. Return 0 if width is unknown
+func writerWidth(w io.Writer) int {
+	if file, ok := w.(*os.File); ok {
+		if width := terminalWidth(file); width > 0 {
+			return width
+		}
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+
+	return 0
+}
+
+// Split text into lines of words not longer than width. Long words are not broken.
+// Text is not wrapped if width is too small to be readable
+func wrapText(text string, width int) []string {
+	words := strings.Fields(text)
+	if width < minWrapWidth || len(words) == 0 {
+		return []string{text}
+	}
+
+	lines := []string{}
+	line := words[0]
+	for _, word := range words[1:] {
+		if len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line = line + " " + word
+	}
+
+	return append(lines, line)
+}
+
+// Return help entries of fields with description, which are not hidden, in order of sortedFields
+func (p *Parser) helpEntries() []HelpEntry {
+	entries := []HelpEntry{}
+	for _, field := range p.sortedFields() {
+		if !field.tags.hasDescription || field.tags.hidden {
+			continue
+		}
+
+		defaultHint := ""
+		if field.tags.hasDefaultValue {
+			defaultHint = fmt.Sprintf("[=%s]", shownDefault(field.tags))
+		}
+		var leftPart = fmt.Sprintf("--%s%s", field.tags.cliName(), defaultHint)
+		if field.tags.short != "" {
+			leftPart = fmt.Sprintf("-%s, %s", field.tags.short, leftPart)
+		}
+		var rightPart = describe(field.tags)
+		if field.tags.example != "" {
+			if len(rightPart) > 0 {
+				rightPart = rightPart + " "
+			}
+			rightPart = fmt.Sprintf("%sEx.: %s", rightPart, field.tags.example)
+		}
+		hint := modesHint(field.tags)
+		if p.helpSourceNames {
+			hint = p.sourcesHint(field)
+		}
+		if len(hint) > 0 {
+			if len(rightPart) > 0 {
+				rightPart = rightPart + " "
+			}
+			rightPart = fmt.Sprintf("%s(%s)", rightPart, hint)
+		}
+		entries = append(entries, HelpEntry{
+			Name:            field.tags.name,
+			Flag:            leftPart,
+			Description:     rightPart,
+			LongDescription: field.tags.longDescription,
+		})
+	}
+
+	return entries
+}
+
+// Return description of field with hints of deprecation and allowed values
+func describe(tags structFieldTags) string {
+	result := tags.description
+	if tags.isDeprecated {
+		if len(result) > 0 {
+			result = result + " "
+		}
+		result = fmt.Sprintf("%s%s", result, deprecationHint(tags))
+	}
+	if len(tags.oneOf) > 0 {
+		if len(result) > 0 {
+			result = result + " "
+		}
+		result = fmt.Sprintf("%s[%s]", result, strings.Join(tags.oneOf, "|"))
+	}
+
+	return result
+}
+
+// Return default value of field as it can be shown to user. Secret values are redacted
+func shownDefault(tags structFieldTags) string {
+	if tags.secret {
+		return redacted
+	}
+
+	return tags.defaultValue
+}
+
+// Describe modes of field for help, if they are limited. Ex.: "cli, cfg only"
+func modesHint(tags structFieldTags) string {
+	if tags.mode == 0 || tags.mode >= modeAll {
+		return ""
+	}
+
+	fieldModes := []string{}
+	for _, title := range modesOrder {
+		if tags.mode&modes[title] > 0 {
+			fieldModes = append(fieldModes, title)
+		}
+	}
+	if len(fieldModes) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s only", strings.Join(fieldModes, ", "))
+}
+
+// Describe sources of field for help with names used in them. Ex.: "cli, cfg db.host, env APP_DB_HOST".
+// Single source is marked as the only one. Ex.: "cli only"
+func (p *Parser) sourcesHint(field *structField) string {
+	result := []string{}
+	for _, mode := range modesOrder {
+		if field.tags.mode != 0 && field.tags.mode&modes[mode] == 0 {
+			continue
+		}
+		switch modes[mode] {
+		case modeCli:
+			result = append(result, mode)
+		case modeCfg:
+			result = append(result, fmt.Sprintf("%s %s", mode, field.tags.cfgName()))
+		case modeEnv:
+			result = append(result, fmt.Sprintf("%s %s", mode, p.envKeys(field.tags)[0]))
+		}
+	}
+	if len(result) == 1 {
+		return result[0] + " only"
+	}
+
+	return strings.Join(result, ", ")
+}
+
+// Check if help is requested with cli arguments. Names claimed by fields are left to them
+func (p *Parser) helpRequested() bool {
+	for _, name := range []string{helpName, helpShortName} {
+		if _, ok := p.parsedCli[name]; ok && !p.isCliNameClaimed(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check if cli name (long name, alias or short name) is used by some field
+func (p *Parser) isCliNameClaimed(name string) bool {
+	for _, field := range p.fields {
+		if field.tags.short == name {
+			return true
+		}
+		if field.tags.mode != 0 && field.tags.mode&modeCli == 0 {
+			continue
+		}
+		for _, fieldName := range append([]string{field.tags.cliName()}, field.tags.aliases...) {
+			if p.sameKey(fieldName, name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Print usage text into help writer. Long descriptions are printed if --help=full is passed
+func (p *Parser) printHelp() error {
+	w := p.helpWriter
+	if w == nil {
+		w = os.Stdout
+	}
+
+	return p.writeUsage(w, p.parsedCli[helpName] == helpFullValue && !p.isCliNameClaimed(helpName))
+}
@@ -0,0 +1,277 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"text/template"
+)
+
+func TestWithAutoHelp(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	type testStruct struct {
+		Port   int    `config:"name:port;mode:cli;default:80;desc:Port to listen"`
+		Prefix string `config:"name:prefix;mode:cli"`
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr error
+	}{
+		{name: "long", args: []string{"/usr/bin/app", "--help"}, want: "Usage: app [options]\n\nOptions:\n    --port[=80] Port to listen (cli only)\n", wantErr: ErrHelpRequested},
+		{name: "short", args: []string{"/usr/bin/app", "--port=8080", "-h"}, want: "Usage: app [options]\n\nOptions:\n    --port[=80] Port to listen (cli only)\n", wantErr: ErrHelpRequested},
+		{name: "not requested", args: []string{"/usr/bin/app", "--port=8080"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = tt.args
+
+			var buf bytes.Buffer
+			var cfg testStruct
+			p, err := NewParser(&cfg, WithAutoHelp(&buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.Parse("", "prefix")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parser.Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Parser.Parse() help = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	os.Args = []string{"/usr/bin/app", "--help"}
+	var cfg testStruct
+	p, _ := NewParser(&cfg)
+	if err := p.Parse("", "prefix"); err != nil {
+		t.Errorf("Parser.Parse() without auto help error = %v", err)
+	}
+}
+
+func TestWithAutoHelp_claimedNames(t *testing.T) {
+	type helpStruct struct {
+		Help bool `config:"name:help;mode:cli;desc:Show help topics"`
+	}
+	type shortStruct struct {
+		Host string `config:"name:host;short:h;mode:cli;desc:Host"`
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		cfg      interface{}
+		want     interface{}
+		wantHelp bool
+	}{
+		{name: "long", args: []string{"/usr/bin/app", "--help"}, cfg: &helpStruct{}, want: &helpStruct{Help: true}},
+		{name: "short of other field", args: []string{"/usr/bin/app", "-h"}, cfg: &helpStruct{}, wantHelp: true},
+		{name: "short", args: []string{"/usr/bin/app", "-h", "localhost"}, cfg: &shortStruct{}, want: &shortStruct{Host: "localhost"}},
+		{name: "long of other field", args: []string{"/usr/bin/app", "--help"}, cfg: &shortStruct{}, wantHelp: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = tt.args
+
+			var buf bytes.Buffer
+			p, err := NewParser(tt.cfg, WithAutoHelp(&buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.Parse("", "")
+			if errors.Is(err, ErrHelpRequested) != tt.wantHelp {
+				t.Fatalf("Parser.Parse() error = %v, wantHelp %v", err, tt.wantHelp)
+			}
+			if !tt.wantHelp && !reflect.DeepEqual(tt.cfg, tt.want) {
+				t.Errorf("Parser.Parse() = %+v, want %+v", tt.cfg, tt.want)
+			}
+			if got := buf.Len() > 0; got != tt.wantHelp {
+				t.Errorf("Parser.Parse() printed help = %v, want %v", got, tt.wantHelp)
+			}
+		})
+	}
+}
+
+func TestParser_WriteUsage(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	type testStruct struct {
+		Port  int    `config:"name:port;default:80;desc:Port to listen"`
+		Level string `config:"name:level;short:l;desc:Log level;oneof:debug,info"`
+	}
+
+	tests := []struct {
+		name string
+		tmpl *template.Template
+		want string
+	}{
+		{name: "default", want: "Usage: app [options]\n\nOptions:\n    -l, --level Log level [debug|info]\n    --port[=80] Port to listen\n"},
+		{
+			name: "header and footer",
+			tmpl: template.Must(template.New("help").Parse("{{.Program}} - test tool\n\n{{.Options}}\nSee docs\n")),
+			want: "app - test tool\n\n    -l, --level Log level [debug|info]\n    --port[=80] Port to listen\n\nSee docs\n",
+		},
+		{
+			name: "entries",
+			tmpl: template.Must(template.New("help").Parse("{{range .Entries}}{{.Name}}: {{.Flag}} - {{.Description}}\n{{end}}")),
+			want: "level: -l, --level - Log level [debug|info]\nport: --port[=80] - Port to listen\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = []string{"/usr/bin/app"}
+
+			var cfg testStruct
+			p, err := NewParser(&cfg, WithHelpTemplate(tt.tmpl))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err = p.WriteUsage(&buf); err != nil {
+				t.Fatalf("Parser.WriteUsage() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Parser.WriteUsage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_HelpWrapped(t *testing.T) {
+	type testStruct struct {
+		Port  int    `config:"name:port;mode:cli;default:80;desc:Port to listen for incoming http connections"`
+		Level string `config:"name:level;mode:cli;desc:Level"`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		width int
+		want  string
+	}{
+		{name: "no wrapping", width: 0, want: "  --level     Level (cli only)\n  --port[=80] Port to listen for incoming http connections (cli only)\n"},
+		{name: "wide", width: 200, want: "  --level     Level (cli only)\n  --port[=80] Port to listen for incoming http connections (cli only)\n"},
+		{name: "wrapped", width: 42, want: "  --level     Level (cli only)\n  --port[=80] Port to listen for incoming\n              http connections (cli only)\n"},
+		{name: "too narrow", width: 30, want: "  --level     Level (cli only)\n  --port[=80] Port to listen for incoming http connections (cli only)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.HelpWrapped("  ", tt.width); got != tt.want {
+				t.Errorf("Parser.HelpWrapped() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Setenv("COLUMNS", "42")
+	var buf bytes.Buffer
+	if err = p.WriteHelp(&buf, "  "); err != nil {
+		t.Fatalf("Parser.WriteHelp() error = %v", err)
+	}
+	if got := buf.String(); got != tests[2].want {
+		t.Errorf("Parser.WriteHelp() = %q, want %q", got, tests[2].want)
+	}
+}
+
+func Test_wrapText(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{text: "", width: 20, want: []string{""}},
+		{text: "short text", width: 20, want: []string{"short text"}},
+		{text: "some words that should be wrapped", width: 20, want: []string{"some words that", "should be wrapped"}},
+		{text: "averyveryveryverylongword and more", width: 20, want: []string{"averyveryveryverylongword", "and more"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := wrapText(tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_Help_example(t *testing.T) {
+	type testStruct struct {
+		DbURL string `config:"name:db_url;mode:env;desc:Database url;example:postgres://user@host/db"`
+		Host  string `config:"name:host;mode:cli;desc:;example:localhost"`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--db_url Database url Ex.: postgres://user@host/db (env only)\n--host   Ex.: localhost (cli only)\n"
+	if got := p.Help(""); got != want {
+		t.Errorf("Parser.Help() = %q, want %q", got, want)
+	}
+}
+
+func TestParser_HelpFull(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	type testStruct struct {
+		Mode string `config:"name:mode;mode:cli;desc:Sync mode;longdesc:In full mode all files are copied. In fast mode just changed ones"`
+		Dry  bool   `config:"name:dry;mode:cli;desc:Dry run"`
+	}
+
+	os.Args = []string{"/usr/bin/app", "--help=full"}
+
+	var buf bytes.Buffer
+	var cfg testStruct
+	p, err := NewParser(&cfg, WithAutoHelp(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); !errors.Is(err, ErrHelpRequested) {
+		t.Fatalf("Parser.Parse() error = %v, wantErr %v", err, ErrHelpRequested)
+	}
+
+	want := "    --dry  Dry run (cli only)\n    --mode Sync mode (cli only)\n           In full mode all files are copied. In fast mode just changed ones\n"
+	if got := buf.String(); got != "Usage: app [options]\n\nOptions:\n"+want {
+		t.Errorf("Parser.Parse() help = %q, want %q", got, want)
+	}
+	if got := p.HelpFull("    "); got != want {
+		t.Errorf("Parser.HelpFull() = %q, want %q", got, want)
+	}
+	if got, want := p.Help(""), "--dry  Dry run (cli only)\n--mode Sync mode (cli only)\n"; got != want {
+		t.Errorf("Parser.Help() = %q, want %q", got, want)
+	}
+}
+
+func TestParser_HelpResolved(t *testing.T) {
+	type testStruct struct {
+		Port     int    `config:"name:port;default:80"`
+		Host     string `config:"name:host;default:localhost"`
+		Password string `config:"name:password;secret"`
+		Timeout  string `config:"name:timeout"`
+		Internal string `config:"name:internal;hidden;default:x"`
+	}
+
+	os.Args = []string{"/app/test", "--port=8080"}
+	t.Setenv("PASSWORD", "qwerty")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	want := "  --host     localhost (default)\n  --password **** (env)\n  --port     8080 (cli)\n  --timeout  (not set)\n"
+	if got := p.HelpResolved("  "); got != want {
+		t.Errorf("Parser.HelpResolved() = %q, want %q", got, want)
+	}
+}
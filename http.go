@@ -0,0 +1,120 @@
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Timeout of fetching config by url, if it is not set with WithHTTPTimeout
+const defaultHTTPTimeout = 10 * time.Second
+
+// Keys - supported content types, values - config format (same as file extension)
+var contentTypes = map[string]string{
+	"application/json": ".json",
+	"text/json":        ".json",
+}
+
+// Check if config file with extension can be parsed
+func isSupportedExt(ext string) bool {
+	for _, supported := range contentTypes {
+		if ext == supported {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Set timeout of fetching config file by http(s) url
+func WithHTTPTimeout(timeout time.Duration) Option {
+	return func(p *Parser) {
+		p.httpTimeout = timeout
+	}
+}
+
+// Add header that will be sent with request of config file by http(s) url. Ex.: authorization token
+func WithHTTPHeader(key, value string) Option {
+	return func(p *Parser) {
+		if p.httpHeaders == nil {
+			p.httpHeaders = make(map[string]string)
+		}
+		p.httpHeaders[key] = value
+	}
+}
+
+// Set TLS settings (custom CA, client certificates etc.) of fetching config file by https url
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(p *Parser) {
+		p.httpTLS = cfg
+	}
+}
+
+// Check if config path is http(s) url instead of file path
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// Download config by url. Return body and config format detected by Content-Type (or by url extension)
+func (p *Parser) fetchCfg(rawURL string) ([]byte, string, error) {
+	timeout := p.httpTimeout
+	if timeout == 0 {
+		timeout = defaultHTTPTimeout
+	}
+
+	client := &http.Client{Timeout: timeout}
+	if p.httpTLS != nil {
+		client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: p.httpTLS,
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	for key, value := range p.httpHeaders {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, "", errors.New(fmt.Sprintf("Cannot fetch config file. Server responded with %s", resp.Status))
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		if ext, ok := contentTypes[mediaType]; ok {
+			return body, ext, nil
+		}
+	}
+
+	// Content-Type is missing or too generic (ex.: text/plain), so try to rely on url
+	if u, err := url.Parse(rawURL); err == nil {
+		if ext := path.Ext(u.Path); ext != "" {
+			if !isSupportedExt(ext) {
+				return nil, "", errors.New(fmt.Sprintf("Unsupported config file extension %s of content type %s", ext, resp.Header.Get("Content-Type")))
+			}
+			return body, ext, nil
+		}
+	}
+
+	return nil, "", errors.New(fmt.Sprintf("Unsupported config content type %s", resp.Header.Get("Content-Type")))
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParser_fetchCfg(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"prefix":"100","nested":{"int":5}}`))
+		case "/plain.json":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(`{"prefix":"200"}`))
+		case "/plain", "/plain.toml":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(`prefix = 100`))
+		case "/auth":
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"prefix":"300"}`))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		case "/broken":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"prefix":"100}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		path    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "json", path: server.URL + "/json", want: map[string]string{"prefix": "100", "nested.int": "5"}},
+		{name: "extension", path: server.URL + "/plain.json", want: map[string]string{"prefix": "200"}},
+		{name: "unknown type", path: server.URL + "/plain", wantErr: true},
+		{name: "unknown extension", path: server.URL + "/plain.toml", wantErr: true},
+		{name: "header", path: server.URL + "/auth", opts: []Option{WithHTTPHeader("Authorization", "Bearer token")}, want: map[string]string{"prefix": "300"}},
+		{name: "no header", path: server.URL + "/auth", wantErr: true},
+		{name: "timeout", path: server.URL + "/slow", opts: []Option{WithHTTPTimeout(50 * time.Millisecond)}, wantErr: true},
+		{name: "broken json", path: server.URL + "/broken", wantErr: true},
+		{name: "not found", path: server.URL + "/zzz.json", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{}
+			for _, opt := range tt.opts {
+				opt(p)
+			}
+			err := p.parseCfg(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parser.parseCfg() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(tt.want, p.parsedCfg) {
+				t.Errorf("Parser.parseCfg() = %v, want %v", p.parsedCfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_fetchCfgTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"prefix":"100"}`))
+	}))
+	defer server.Close()
+
+	p := &Parser{}
+	if err := p.parseCfg(server.URL); err == nil {
+		t.Errorf("Parser.parseCfg() should fail with unknown certificate authority")
+	}
+
+	WithTLSConfig(server.Client().Transport.(*http.Transport).TLSClientConfig)(p)
+	if err := p.parseCfg(server.URL); err != nil {
+		t.Errorf("Parser.parseCfg() error = %v", err)
+	}
+	if p.parsedCfg["prefix"] != "100" {
+		t.Errorf("Parser.parseCfg() = %v", p.parsedCfg)
+	}
+}
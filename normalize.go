@@ -0,0 +1,38 @@
+package config
+
+// Set function that normalizes keys of all sources (cli flags, config file and external sources keys, env names)
+// before they are compared with names of fields. Ex.: replacing dashes with underscores, lowercasing.
+// It is applied to names of fields too, so heterogeneous config files can be unified without renaming keys
+func WithKeyNormalizer(normalize func(key string) string) Option {
+	return func(p *Parser) {
+		p.normalizeKey = normalize
+	}
+}
+
+// Check if key of source matches name of field, after normalization
+func (p *Parser) sameKey(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	return p.normalizeKey != nil && p.normalizeKey(a) == p.normalizeKey(b)
+}
+
+// Return key of values that matches name. Exact match has priority over normalized one. If several keys
+// are normalized to the same name, first of them in sorted order wins, so result doesn't depend on map order.
+// Name itself is returned if there is no such key
+func (p *Parser) keyOf(values map[string]string, name string) string {
+	if _, ok := values[name]; ok || p.normalizeKey == nil {
+		return name
+	}
+
+	normalized := p.normalizeKey(name)
+	result, found := name, false
+	for key := range values {
+		if p.normalizeKey(key) == normalized && (!found || key < result) {
+			result, found = key, true
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithKeyNormalizer(t *testing.T) {
+	type testStruct struct {
+		Config   string `config:"name:config;mode:cli"`
+		LogLevel string `config:"name:log_level;mode:cli,cfg"`
+		DbHost   string `config:"name:db_host;mode:cfg"`
+		Verbose  bool   `config:"name:verbose;mode:cli"`
+		Timeout  int    `config:"name:timeout-sec;mode:env"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"Log-Level":"debug","DB-Host":"localhost"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	normalize := func(key string) string {
+		return strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	}
+
+	os.Args = []string{"/app/test", "--config", path, "--Verbose", "file.txt"}
+	t.Setenv("TIMEOUT_SEC", "30")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg, WithKeyNormalizer(normalize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("config", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	want := testStruct{Config: path, LogLevel: "debug", DbHost: "localhost", Verbose: true, Timeout: 30}
+	if cfg != want {
+		t.Errorf("Parser.Parse() = %+v, want %+v", cfg, want)
+	}
+	if got := p.Args(); len(got) != 1 || got[0] != "file.txt" {
+		t.Errorf("Parser.Args() = %v", got)
+	}
+
+	os.Args = []string{"/app/test", "--log-level=info"}
+	p, _ = NewParser(&cfg, WithKeyNormalizer(normalize))
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if cfg.LogLevel != "info" {
+		t.Errorf("Parser.Parse() log level = %v, want %v", cfg.LogLevel, "info")
+	}
+}
+
+func TestParser_keyOf(t *testing.T) {
+	values := map[string]string{"Log-Level": "debug", "LOG_LEVEL": "info", "log-level": "warn", "port": "80"}
+	p := &Parser{normalizeKey: func(key string) string {
+		return strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	}}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "port", want: "port"},
+		{name: "log-level", want: "log-level"},
+		{name: "log_level", want: "LOG_LEVEL"},
+		{name: "zzz", want: "zzz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ { // Map order is random, so result is checked several times
+				if got := p.keyOf(values, tt.name); got != tt.want {
+					t.Fatalf("Parser.keyOf() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
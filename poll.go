@@ -0,0 +1,177 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Delay before restart of broken watch of source
+const watchRetryDelay = 5 * time.Second
+
+// Re-fetch external sources every interval after Parse. If values are changed, they are written into config struct
+// (so readers should use Value bound to parser or own synchronization) and handler set with WithOnChange is called.
+// Failed fetches are reported to warning handler, and previous values are kept. Polling is stopped by Close
+func WithPollInterval(interval time.Duration) Option {
+	return func(p *Parser) {
+		p.pollInterval = interval
+	}
+}
+
+// Watch external sources that support it (see WatchableSource) after Parse. When source reports change, sources
+// are fetched and changed values are applied like polling does (see WithPollInterval). Broken watches are reported
+// to warning handler and restarted. Watching is stopped by Close
+func WithWatch() Option {
+	return func(p *Parser) {
+		p.watch = true
+	}
+}
+
+// Set handler that is called after config struct is refilled with changed values
+func WithOnChange(handler func()) Option {
+	return func(p *Parser) {
+		p.onChange = handler
+	}
+}
+
+// Stop polling and watching of external sources
+func (p *Parser) Close() {
+	if p.mu == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pollStop != nil {
+		close(p.pollStop)
+		p.pollStop = nil
+	}
+}
+
+// Start polling goroutine and watches of sources if they are enabled and not started yet. Changes found by watches
+// are applied by polling goroutine, so sources are not fetched concurrently. Should be called under lock
+func (p *Parser) startPolling() {
+	isPolling := p.pollInterval > 0 && len(p.sources) > 0
+	watched := p.watchedSources()
+	if !isPolling && len(watched) == 0 || p.pollStop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	p.pollStop = stop
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan struct{}, 1)
+	watchErrors := make(chan error)
+	for _, src := range watched {
+		go watchSource(ctx, src, changes, watchErrors)
+	}
+
+	go func() {
+		defer cancel()
+
+		var ticks <-chan time.Time
+		if isPolling {
+			ticker := time.NewTicker(p.pollInterval)
+			defer ticker.Stop()
+			ticks = ticker.C
+		}
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticks:
+				p.poll()
+			case <-changes:
+				p.poll()
+			case err := <-watchErrors: // Warnings are reported here, so handler is not called concurrently
+				if p.onWarning != nil {
+					p.onWarning(err)
+				}
+			}
+		}
+	}()
+}
+
+// Return sources that should be watched
+func (p *Parser) watchedSources() []WatchableSource {
+	result := []WatchableSource{}
+	if !p.watch {
+		return result
+	}
+	for _, src := range p.sources {
+		if watchable, ok := src.(WatchableSource); ok {
+			result = append(result, watchable)
+		}
+	}
+
+	return result
+}
+
+// Watch source until ctx is done, signaling its changes. Broken watch is reported and restarted after delay
+func watchSource(ctx context.Context, src WatchableSource, changes chan<- struct{}, watchErrors chan<- error) {
+	for {
+		err := src.Watch(ctx, func() {
+			select {
+			case changes <- struct{}{}:
+			default: // Pending change is not applied yet, and it will fetch this one too
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("watching is stopped")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case watchErrors <- fmt.Errorf("Watch of %s is broken: %w", src.Name(), err):
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryDelay):
+		}
+	}
+}
+
+// Fetch external sources and apply their values if they are changed
+func (p *Parser) poll() {
+	values, origins, _, err := p.fetchSources(true)
+	if err != nil {
+		if p.onWarning != nil {
+			p.onWarning(err)
+		}
+		return
+	}
+
+	p.mu.Lock()
+	merged := copyValues(p.parsedFile)
+	for k, v := range values {
+		merged[k] = v
+	}
+
+	previous, previousOrigins := p.parsedCfg, p.cfgOrigins
+	p.parsedCfg, p.cfgOrigins = merged, origins
+	changed, err := p.refill()
+	if err != nil {
+		p.parsedCfg, p.cfgOrigins = previous, previousOrigins
+	}
+	p.mu.Unlock()
+
+	if err != nil {
+		if p.onWarning != nil {
+			p.onWarning(err)
+		}
+		return
+	}
+
+	if len(changed) > 0 && p.onChange != nil {
+		p.onChange()
+	}
+}
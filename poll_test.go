@@ -0,0 +1,180 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Source which values can be changed while polling
+type mutableSource struct {
+	mu     sync.Mutex
+	values map[string]string
+	err    error
+}
+
+func (s *mutableSource) Name() string {
+	return "mutable"
+}
+
+func (s *mutableSource) Load(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyValues(s.values), s.err
+}
+
+func (s *mutableSource) set(values map[string]string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = values
+	s.err = err
+}
+
+func TestParser_poll(t *testing.T) {
+	type testStruct struct {
+		Port    int `config:"name:port;mode:cfg"`
+		Ignored string
+	}
+
+	os.Args = []string{"/app/test"}
+
+	src := &mutableSource{values: map[string]string{"port": "8080"}}
+	changes := make(chan struct{}, 10)
+	warnings := make(chan error, 10)
+
+	cfg := testStruct{Ignored: "keep"}
+	p, err := NewParser(&cfg,
+		WithSource(src),
+		WithPollInterval(10*time.Millisecond),
+		WithOnChange(func() { changes <- struct{}{} }),
+		WithWarningHandler(func(err error) { warnings <- err }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if cfg.Port != 8080 {
+		t.Errorf("Parser.Parse() port = %v, want 8080", cfg.Port)
+	}
+
+	src.set(map[string]string{"port": "9090"}, nil)
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatalf("Change is not detected")
+	}
+	p.mu.Lock()
+	if cfg.Port != 9090 || cfg.Ignored != "keep" {
+		t.Errorf("Polled config = %v", cfg)
+	}
+	p.mu.Unlock()
+
+	// Failed fetch keeps previous values
+	src.set(nil, errors.New("unavailable"))
+	select {
+	case <-warnings:
+	case <-time.After(time.Second):
+		t.Fatalf("Failed fetch is not reported")
+	}
+
+	// Broken value keeps previous values
+	src.set(map[string]string{"port": "ZZZ"}, nil)
+	select {
+	case <-warnings:
+	case <-time.After(time.Second):
+		t.Fatalf("Broken value is not reported")
+	}
+	p.mu.Lock()
+	if cfg.Port != 9090 {
+		t.Errorf("Polled config = %v, want port 9090", cfg)
+	}
+	p.mu.Unlock()
+
+	p.Close()
+	src.set(map[string]string{"port": "1"}, nil)
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case <-changes:
+		t.Errorf("Change is detected after Close")
+	default:
+	}
+}
+
+// Source which notifies watcher about changes of its values
+type pushSource struct {
+	mutableSource
+	pushes chan struct{}
+	broken chan error
+}
+
+func (s *pushSource) Watch(ctx context.Context, onChange func()) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-s.broken:
+			return err
+		case <-s.pushes:
+			onChange()
+		}
+	}
+}
+
+func TestWithWatch(t *testing.T) {
+	type testStruct struct {
+		Port int `config:"name:port;mode:cfg"`
+	}
+
+	os.Args = []string{"/app/test"}
+
+	src := &pushSource{mutableSource: mutableSource{values: map[string]string{"port": "8080"}}, pushes: make(chan struct{}), broken: make(chan error)}
+	changes := make(chan struct{}, 10)
+	warnings := make(chan error, 10)
+
+	var cfg testStruct
+	p, err := NewParser(&cfg,
+		WithSource(src),
+		WithWatch(),
+		WithOnChange(func() { changes <- struct{}{} }),
+		WithWarningHandler(func(err error) { warnings <- err }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	src.set(map[string]string{"port": "9090"}, nil)
+	src.pushes <- struct{}{}
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatalf("Pushed change is not applied")
+	}
+	p.mu.Lock()
+	if cfg.Port != 9090 {
+		t.Errorf("Watched config = %v, want port 9090", cfg)
+	}
+	p.mu.Unlock()
+
+	src.broken <- errors.New("connection reset")
+	select {
+	case err := <-warnings:
+		if !strings.Contains(err.Error(), "connection reset") {
+			t.Errorf("Watch warning = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Broken watch is not reported")
+	}
+}
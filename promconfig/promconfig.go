@@ -0,0 +1,39 @@
+// Package promconfig exposes config generation of github.com/zamaldinov28/config parser as Prometheus metric
+package promconfig
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zamaldinov28/config"
+)
+
+// Collector of config_info{hash="..."} gauge. Hash is read from parser on each scrape, so it follows reloads
+type Collector struct {
+	parser *config.Parser
+	desc   *prometheus.Desc
+}
+
+// Create collector of parser's config hash. Metric name is <namespace>_config_info (or config_info if namespace is empty)
+func NewCollector(p *config.Parser, namespace string) *Collector {
+	return &Collector{
+		parser: p,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "config", "info"),
+			"Hash of effective configuration. Value is always 1",
+			[]string{"hash"},
+			nil,
+		),
+	}
+}
+
+// Register collector of parser's config hash
+func Register(reg prometheus.Registerer, p *config.Parser, namespace string) error {
+	return reg.Register(NewCollector(p, namespace))
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1, c.parser.Hash())
+}
@@ -0,0 +1,54 @@
+package promconfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zamaldinov28/config"
+)
+
+func TestRegister(t *testing.T) {
+	type testStruct struct {
+		Port int `config:"name:port;mode:env"`
+	}
+
+	os.Args = []string{"/app/test"}
+	t.Setenv("PORT", "8080")
+
+	var cfg testStruct
+	p, err := config.NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := prometheus.NewRegistry()
+	if err = Register(reg, &p, "app"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 1 || families[0].GetName() != "app_config_info" {
+		t.Fatalf("Gather() = %v", families)
+	}
+	metric := families[0].GetMetric()[0]
+	if metric.GetGauge().GetValue() != 1 || metric.GetLabel()[0].GetValue() != p.Hash() {
+		t.Errorf("Gather() metric = %v, want hash %v", metric, p.Hash())
+	}
+
+	// Hash follows reloads
+	t.Setenv("PORT", "9090")
+	if _, err = p.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	families, _ = reg.Gather()
+	if got := families[0].GetMetric()[0].GetLabel()[0].GetValue(); got != p.Hash() {
+		t.Errorf("Gather() hash = %v, want %v", got, p.Hash())
+	}
+}
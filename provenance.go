@@ -0,0 +1,30 @@
+package config
+
+// Origin of config value
+type SourceInfo struct {
+	Source string // cli, cfg, env, default or name of external source
+	Key    string // Key of value in its source: flag (ex.: "--db.host"), env variable or config file key. Empty for default
+	Remote bool   // Value came from external source
+}
+
+// Return origins of values written into config struct by last Parse or Reload. Keys - config names.
+// Fields that got no value (not even default) are missing
+func (p *Parser) Provenance() map[string]SourceInfo {
+	if p.mu != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+	}
+
+	result := make(map[string]SourceInfo, len(p.valueSources))
+	for name, source := range p.valueSources {
+		info := SourceInfo{Source: source, Key: p.valueKeys[name]}
+		switch source {
+		case sourceCli, sourceCfg, sourceEnv, sourceDefault:
+		default:
+			info.Remote = true
+		}
+		result[name] = info
+	}
+
+	return result
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParser_Provenance(t *testing.T) {
+	type testStruct struct {
+		Port     int      `config:"name:port"`
+		Host     string   `config:"name:host;default:localhost"`
+		Level    string   `config:"name:level;alias:log_level"`
+		Password string   `config:"name:password;secret"`
+		Timeout  string   `config:"name:timeout"`
+		Hosts    []string `config:"name:hosts"`
+		Config   string   `config:"name:config"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"timeout": "5s"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Args = []string{"/app/test", "--log_level=debug", "--config=" + path}
+	t.Setenv("PORT", "8080")
+	t.Setenv("HOSTS_0", "a")
+
+	var cfg testStruct
+	src := &staticSource{name: "vault", values: map[string]string{"password": "qwerty"}}
+	p, err := NewParser(&cfg, WithSource(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("config", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	want := map[string]SourceInfo{
+		"port":     {Source: "env", Key: "PORT"},
+		"host":     {Source: "default"},
+		"level":    {Source: "cli", Key: "--log_level"},
+		"password": {Source: "vault", Key: "password", Remote: true},
+		"hosts":    {Source: "env", Key: "HOSTS"},
+		"timeout":  {Source: "cfg", Key: "timeout"},
+		"config":   {Source: "cli", Key: "--config"},
+	}
+	if got := p.Provenance(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parser.Provenance() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Render config struct (or pointer to it) like fmt does with %+v, but with values of fields with secret tag replaced
+// by ****, so it is safe for logs. Nested structs and slices of structs are rendered the same way.
+// It can implement String method of config struct, so log.Printf("%+v", cfg) doesn't leak credentials:
+//
+//	func (c Config) String() string {
+//		return config.Redact(c)
+//	}
+func Redact(v interface{}) string {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Sprintf("%+v", v)
+	}
+
+	buffer := bytes.NewBufferString("")
+	writeRedactedStruct(buffer, value)
+	return buffer.String()
+}
+
+// Write struct with redacted secret fields. Own String method of struct is not called, so Redact can be used in it
+func writeRedactedStruct(buffer *bytes.Buffer, v reflect.Value) {
+	buffer.WriteString("{")
+	for i := 0; i < v.NumField(); i++ {
+		if i > 0 {
+			buffer.WriteString(" ")
+		}
+		field := v.Type().Field(i)
+		buffer.WriteString(field.Name + ":")
+		if isSecretField(field) {
+			buffer.WriteString(redacted)
+			continue
+		}
+		writeRedactedValue(buffer, v.Field(i))
+	}
+	buffer.WriteString("}")
+}
+
+// Write value of struct field. Nested structs without own String method are rendered with redacted secrets too
+func writeRedactedValue(buffer *bytes.Buffer, v reflect.Value) {
+	switch {
+	case v.Kind() == reflect.Struct && !isStringer(v.Type()):
+		writeRedactedStruct(buffer, v)
+	case v.Kind() == reflect.Pointer && !v.IsNil() && v.Elem().Kind() == reflect.Struct && !isStringer(v.Type()):
+		buffer.WriteString("&")
+		writeRedactedStruct(buffer, v.Elem())
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() == reflect.Struct && !isStringer(v.Type().Elem()):
+		buffer.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buffer.WriteString(" ")
+			}
+			writeRedactedStruct(buffer, v.Index(i))
+		}
+		buffer.WriteString("]")
+	default:
+		buffer.WriteString(fmt.Sprintf("%+v", v))
+	}
+}
+
+// Check if type has String or Error method, that fmt uses instead of fields
+func isStringer(t reflect.Type) bool {
+	stringer := reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+
+	return t.Implements(stringer) || t.Implements(errorType)
+}
+
+// Check if config tag of field has secret flag
+func isSecretField(field reflect.StructField) bool {
+	for _, flag := range strings.Split(field.Tag.Get(tag), separator) {
+		name, value, _ := strings.Cut(flag, separatorInner)
+		if name == tagSecret {
+			secret, err := parseBoolTag(name, value)
+			return secret || err != nil // Broken flag is treated as secret, so value isn't leaked
+		}
+	}
+
+	return false
+}
+
+// Return raw value of field as it can be shown to user. Secret value is redacted, as well as values of secret fields
+// inside of it: in items of slice of structs and in json value (fields with secret flag in config tag)
+func (p *Parser) shownValue(field *structField, value string) string {
+	if field.tags.secret {
+		return redacted
+	}
+
+	t := p.fieldType(field)
+	if t == nil || field.tags.encoding != encodingJSON && (t.Kind() != reflect.Slice || !isNestedStruct(t.Elem())) {
+		return value
+	}
+
+	var decoded interface{}
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber() // Numbers are kept as they are
+	if err := decoder.Decode(&decoded); err != nil {
+		return value
+	}
+	if !redactDecoded(decoded, t, field.tags.encoding == encodingJSON) {
+		return value // Value without secrets is shown as it is written
+	}
+
+	buffer := bytes.NewBufferString("")
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(decoded); err != nil {
+		return redacted
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// Replace values of secret fields in decoded json value of type. Keys of structs are json names of fields if isJSON,
+// or config names for items of slice of structs. Return true if some value is replaced
+func redactDecoded(value interface{}, t reflect.Type, isJSON bool) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	isRedacted := false
+	switch decoded := value.(type) {
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return false
+		}
+		for _, item := range decoded {
+			isRedacted = redactDecoded(item, t.Elem(), isJSON) || isRedacted
+		}
+	case map[string]interface{}:
+		switch {
+		case t.Kind() == reflect.Map && isJSON:
+			for _, item := range decoded {
+				isRedacted = redactDecoded(item, t.Elem(), isJSON) || isRedacted
+			}
+		case t.Kind() == reflect.Struct && isJSON:
+			isRedacted = redactJSONObject(decoded, t)
+		case t.Kind() == reflect.Struct:
+			isRedacted = redactItemObject(decoded, t)
+		}
+	}
+
+	return isRedacted
+}
+
+// Replace values of secret fields in object decoded from json into struct type. Keys are matched like encoding/json does
+func redactJSONObject(object map[string]interface{}, t reflect.Type) bool {
+	isRedacted := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get(jsonTag), ",")
+		if name == tagIgnore || !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if name == "" && field.Anonymous && fieldType.Kind() == reflect.Struct { // Fields of embedded struct are promoted
+			isRedacted = redactJSONObject(object, fieldType) || isRedacted
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		for key, item := range object {
+			if !strings.EqualFold(key, name) {
+				continue
+			}
+			if isSecretField(field) {
+				object[key] = redacted
+				isRedacted = true
+				continue
+			}
+			isRedacted = redactDecoded(item, field.Type, true) || isRedacted
+		}
+	}
+
+	return isRedacted
+}
+
+// Replace values of secret fields in item of slice of structs. Keys are config names of fields of item struct
+func redactItemObject(object map[string]interface{}, t reflect.Type) bool {
+	itemParser, err := NewParser(reflect.New(t).Interface())
+	if err != nil {
+		return false
+	}
+
+	isRedacted := false
+	for _, field := range itemParser.fields {
+		for _, name := range append([]string{field.tags.cfgName()}, field.tags.aliases...) {
+			parent, key, ok := nestedKey(object, strings.Split(name, itemParser.nestedSeparator()), itemParser.nestedSeparator())
+			if !ok {
+				continue
+			}
+			if field.tags.secret {
+				parent[key] = redacted
+				isRedacted = true
+				continue
+			}
+			if t := itemParser.fieldType(field); t != nil {
+				isRedacted = redactDecoded(parent[key], t, field.tags.encoding == encodingJSON) || isRedacted
+			}
+		}
+	}
+
+	return isRedacted
+}
+
+// Find key of nested objects by path. Path parts can be joined by separator in key itself. Ex.: "tls.key".
+// Return object that has the key and the key itself
+func nestedKey(object map[string]interface{}, path []string, sep string) (map[string]interface{}, string, bool) {
+	for i := len(path); i > 0; i-- {
+		key := strings.Join(path[:i], sep)
+		value, ok := object[key]
+		if !ok {
+			continue
+		}
+		if i == len(path) {
+			return object, key, true
+		}
+		if child, isObject := value.(map[string]interface{}); isObject {
+			if parent, childKey, found := nestedKey(child, path[i:], sep); found {
+				return parent, childKey, true
+			}
+		}
+	}
+
+	return nil, "", false
+}
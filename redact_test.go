@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+type redactTestServer struct {
+	Host  string `config:"name:host"`
+	Token string `config:"name:token;secret"`
+}
+
+type redactTestConfig struct {
+	Port     int                `config:"name:port"`
+	Password string             `config:"name:password;secret"`
+	Timeout  time.Duration      `config:"name:timeout"`
+	Servers  []redactTestServer `config:"name:servers"`
+	DB       *struct {
+		Pass string `config:"name:pass;secret:yes"`
+	} `config:"name:db"`
+	Debug  bool `config:"name:debug;secret:false"`
+	hidden string
+}
+
+func (c redactTestConfig) String() string {
+	return Redact(c)
+}
+
+func TestRedact(t *testing.T) {
+	cfg := redactTestConfig{
+		Port:     8080,
+		Password: "qwerty",
+		Timeout:  time.Minute,
+		Servers:  []redactTestServer{{Host: "a", Token: "t1"}},
+		hidden:   "x",
+	}
+	cfg.DB = &struct {
+		Pass string `config:"name:pass;secret:yes"`
+	}{Pass: "p"}
+
+	want := "{Port:8080 Password:**** Timeout:1m0s Servers:[{Host:a Token:****}] DB:&{Pass:****} Debug:false hidden:x}"
+	tests := []struct {
+		name string
+		got  string
+	}{
+		{name: "value", got: Redact(cfg)},
+		{name: "pointer", got: Redact(&cfg)},
+		{name: "String method", got: fmt.Sprintf("%+v", cfg)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != want {
+				t.Errorf("Redact() = %v, want %v", tt.got, want)
+			}
+		})
+	}
+
+	if got := Redact(42); got != "42" {
+		t.Errorf("Redact() = %v, want 42", got)
+	}
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Address of local Redis, used if RedisSource.Address is empty
+const defaultRedisAddress = "127.0.0.1:6379"
+
+// Separator of Redis key parts. Ex.: key "app:db:host" with prefix "app:" gives "db.host"
+const redisSeparator = ":"
+
+// Source that reads string keys under prefix (or fields of single hash) from Redis.
+// If Channel is set, Watch subscribes to it and reports any published message as change of values
+type RedisSource struct {
+	Address  string // host:port. Default is 127.0.0.1:6379
+	Username string // ACL user (Redis 6+). Can be empty if just password is used
+	Password string
+	DB       int    // Database number
+	Prefix   string // Keys prefix. Ex.: app:
+	Hash     string // Name of hash to read instead of keys with prefix
+	Channel  string // Pub/sub channel with invalidation messages
+}
+
+// Minimal RESP protocol client, enough for reading config
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (s *RedisSource) Name() string {
+	return "redis"
+}
+
+// Read all keys with prefix, or all fields of hash
+func (s *RedisSource) Load(ctx context.Context) (map[string]string, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		c.conn.SetDeadline(deadline)
+	}
+
+	result := make(map[string]string)
+
+	if s.Hash != "" {
+		reply, err := c.do("HGETALL", s.Hash)
+		if err != nil {
+			return nil, err
+		}
+		pairs, _ := reply.([]interface{})
+		for i := 0; i+1 < len(pairs); i += 2 {
+			key, _ := pairs[i].(string)
+			value, _ := pairs[i+1].(string)
+			result[strings.ReplaceAll(key, redisSeparator, separatorNested)] = value
+		}
+
+		return result, nil
+	}
+
+	keys := []string{}
+	cursor := "0"
+	for {
+		reply, err := c.do("SCAN", cursor, "MATCH", redisEscapePattern(s.Prefix)+"*", "COUNT", "100")
+		if err != nil {
+			return nil, err
+		}
+		parts, _ := reply.([]interface{})
+		if len(parts) != 2 {
+			return nil, errors.New("Unexpected Redis SCAN reply")
+		}
+		cursor, _ = parts[0].(string)
+		found, _ := parts[1].([]interface{})
+		for _, key := range found {
+			if k, ok := key.(string); ok {
+				keys = append(keys, k)
+			}
+		}
+		if cursor == "0" {
+			break
+		}
+	}
+
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	reply, err := c.do("MGET", keys...)
+	if err != nil {
+		return nil, err
+	}
+	values, _ := reply.([]interface{})
+	for i, key := range keys {
+		if i >= len(values) {
+			break
+		}
+		value, ok := values[i].(string)
+		if !ok { // Not a string key
+			continue
+		}
+		name := strings.Trim(strings.TrimPrefix(key, s.Prefix), redisSeparator)
+		if name == "" {
+			continue
+		}
+		result[strings.ReplaceAll(name, redisSeparator, separatorNested)] = value
+	}
+
+	return result, nil
+}
+
+// Subscribe to invalidation channel. Block until ctx is done or connection is broken
+func (s *RedisSource) Watch(ctx context.Context, onChange func()) error {
+	if s.Channel == "" {
+		return errors.New("Redis channel is not set")
+	}
+
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.conn.Close()
+
+	_, err = c.do("SUBSCRIBE", s.Channel)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		reply, err := c.read()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		msg, _ := reply.([]interface{})
+		if len(msg) == 3 && msg[0] == "message" {
+			onChange()
+		}
+	}
+}
+
+// Open connection, authenticate and select database
+func (s *RedisSource) connect(ctx context.Context) (*redisConn, error) {
+	address := s.Address
+	if address == "" {
+		address = defaultRedisAddress
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+
+	if s.Password != "" {
+		args := []string{s.Password}
+		if s.Username != "" {
+			args = []string{s.Username, s.Password}
+		}
+		if _, err = c.do("AUTH", args...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	if s.DB != 0 {
+		if _, err = c.do("SELECT", strconv.Itoa(s.DB)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Send command and read its reply
+func (c *redisConn) do(cmd string, args ...string) (interface{}, error) {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("*%d\r\n$%d\r\n%s\r\n", len(args)+1, len(cmd), cmd))
+	for _, arg := range args {
+		b.WriteString(fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg))
+	}
+
+	_, err := io.WriteString(c.conn, b.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return c.read()
+}
+
+// Read single reply. Bulk strings are returned as string, arrays as []interface{}, nil values as nil
+func (c *redisConn) read() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("Empty Redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, errors.New(fmt.Sprintf("Redis error: %s", line[1:]))
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2) // With trailing \r\n
+		_, err = io.ReadFull(c.r, buf)
+		if err != nil {
+			return nil, err
+		}
+		return string(buf[:size]), nil
+	case '*':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, size)
+		for i := range items {
+			items[i], err = c.read()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("Unknown Redis reply: %s", line))
+}
+
+// Escape glob special chars of prefix to use it in MATCH pattern
+func redisEscapePattern(prefix string) string {
+	var b strings.Builder
+	for _, r := range prefix {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Guards data of fake Redis server, so test can change it while server is running
+var fakeRedisMu sync.Mutex
+
+// Fake Redis server that supports just commands used by RedisSource. Messages are published into subscribed channel
+// until messages channel is closed
+func newFakeRedis(t *testing.T, data map[string]string, hashes map[string]map[string]string, messages <-chan string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				c := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+				for {
+					reply, err := c.read()
+					if err != nil {
+						return
+					}
+					args := []string{}
+					for _, arg := range reply.([]interface{}) {
+						args = append(args, arg.(string))
+					}
+					switch args[0] {
+					case "AUTH":
+						if args[len(args)-1] != "pass" {
+							fmt.Fprint(conn, "-WRONGPASS invalid password\r\n")
+							continue
+						}
+						fmt.Fprint(conn, "+OK\r\n")
+					case "SELECT":
+						fmt.Fprint(conn, "+OK\r\n")
+					case "SCAN":
+						fakeRedisMu.Lock()
+						prefix := strings.TrimSuffix(args[3], "*")
+						keys := []string{}
+						for k := range data {
+							if strings.HasPrefix(k, prefix) {
+								keys = append(keys, k)
+							}
+						}
+						fakeRedisMu.Unlock()
+						fmt.Fprintf(conn, "*2\r\n$1\r\n0\r\n*%d\r\n", len(keys))
+						for _, k := range keys {
+							fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(k), k)
+						}
+					case "MGET":
+						fmt.Fprintf(conn, "*%d\r\n", len(args)-1)
+						for _, k := range args[1:] {
+							fakeRedisMu.Lock()
+							v, ok := data[k]
+							fakeRedisMu.Unlock()
+							if ok && v != "" {
+								fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(v), v)
+							} else {
+								fmt.Fprint(conn, "$-1\r\n")
+							}
+						}
+					case "HGETALL":
+						fmt.Fprintf(conn, "*%d\r\n", len(hashes[args[1]])*2)
+						for k, v := range hashes[args[1]] {
+							fmt.Fprintf(conn, "$%d\r\n%s\r\n$%d\r\n%s\r\n", len(k), k, len(v), v)
+						}
+					case "SUBSCRIBE":
+						fmt.Fprintf(conn, "*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(args[1]), args[1])
+						for msg := range messages {
+							fmt.Fprintf(conn, "*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(args[1]), args[1], len(msg), msg)
+						}
+					default:
+						fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestRedisSource_Load(t *testing.T) {
+	address := newFakeRedis(t,
+		map[string]string{"app:port": "8080", "app:db:host": "localhost", "app:list": "", "other:port": "1"},
+		map[string]map[string]string{"app": {"port": "9090", "db:host": "db"}},
+		nil,
+	)
+
+	tests := []struct {
+		name    string
+		source  RedisSource
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "prefix", source: RedisSource{Address: address, Prefix: "app:"}, want: map[string]string{"port": "8080", "db.host": "localhost"}},
+		{name: "hash", source: RedisSource{Address: address, Hash: "app", DB: 1}, want: map[string]string{"port": "9090", "db.host": "db"}},
+		{name: "auth", source: RedisSource{Address: address, Prefix: "other:", Username: "user", Password: "pass"}, want: map[string]string{"port": "1"}},
+		{name: "wrong auth", source: RedisSource{Address: address, Prefix: "other:", Password: "zzz"}, wantErr: true},
+		{name: "empty", source: RedisSource{Address: address, Prefix: "zzz:"}, want: map[string]string{}},
+		{name: "unavailable", source: RedisSource{Address: "127.0.0.1:1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.source.Load(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RedisSource.Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RedisSource.Load() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedisSource_Watch(t *testing.T) {
+	messages := make(chan string, 2)
+	messages <- "reload"
+	messages <- "reload"
+	close(messages)
+	address := newFakeRedis(t, nil, nil, messages)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan struct{}, 10)
+	done := make(chan error)
+	source := RedisSource{Address: address, Channel: "config"}
+	go func() {
+		done <- source.Watch(ctx, func() { changes <- struct{}{} })
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-changes:
+		case <-time.After(time.Second):
+			t.Fatalf("RedisSource.Watch() got %v changes, want %v", i, 2)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("RedisSource.Watch() error = %v", err)
+	}
+
+	if err := (&RedisSource{Address: address}).Watch(context.Background(), func() {}); err == nil {
+		t.Errorf("RedisSource.Watch() should fail without channel")
+	}
+}
+
+func Test_redisEscapePattern(t *testing.T) {
+	if got := redisEscapePattern(`app:[a]*?\`); got != `app:\[a\]\*\?\\` {
+		t.Errorf("redisEscapePattern() = %v", got)
+	}
+}
+
+func TestRedisSource_watchedByParser(t *testing.T) {
+	type testStruct struct {
+		Port int `config:"name:port;mode:cfg"`
+	}
+
+	data := map[string]string{"app:port": "8080"}
+	messages := make(chan string)
+	defer close(messages)
+	address := newFakeRedis(t, data, nil, messages)
+
+	os.Args = []string{"/app/test"}
+	changes := make(chan struct{}, 10)
+
+	var cfg testStruct
+	p, err := NewParser(&cfg,
+		WithSource(&RedisSource{Address: address, Prefix: "app:", Channel: "config"}),
+		WithWatch(),
+		WithOnChange(func() { changes <- struct{}{} }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	fakeRedisMu.Lock()
+	data["app:port"] = "9090"
+	fakeRedisMu.Unlock()
+	messages <- "reload"
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatalf("Published change is not applied")
+	}
+	p.mu.Lock()
+	if cfg.Port != 9090 {
+		t.Errorf("Watched config = %v, want port 9090", cfg)
+	}
+	p.mu.Unlock()
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// Re-read all sources (cli, env, config file, external sources) with settings of last Parse call, and refill config struct.
+// Return sorted names of changed configs. Struct and values read from sources (used by polling, Provenance
+// and UnusedKeys) are not changed if reloading is failed
+func (p *Parser) Reload() ([]string, error) {
+	if p.mu == nil { // Mutex is made by Parse
+		return nil, errors.New("Parse should be called before Reload")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.isParsed {
+		return nil, errors.New("Parse should be called before Reload")
+	}
+
+	return p.parse()
+}
+
+// Values read from sources by parse. They are restored if parsing is failed, so rejected values are not used
+// by polling or reported by Provenance and UnusedKeys
+type parseState struct {
+	envPrefix   string
+	parsedCfg   map[string]string
+	parsedCli   map[string]string
+	args        []string
+	parsedEnv   map[string]string
+	parsedFile  map[string]string
+	cfgOrigins  map[string]string
+	envSnapshot map[string]string
+	dotenv      map[string]string
+	warnings    []error
+}
+
+// Save values read from sources. Should be called under lock
+func (p *Parser) saveParseState() parseState {
+	return parseState{
+		envPrefix:   p.envPrefix,
+		parsedCfg:   p.parsedCfg,
+		parsedCli:   p.parsedCli,
+		args:        p.args,
+		parsedEnv:   p.parsedEnv,
+		parsedFile:  p.parsedFile,
+		cfgOrigins:  p.cfgOrigins,
+		envSnapshot: p.envSnapshot,
+		dotenv:      p.dotenv,
+		warnings:    p.warnings,
+	}
+}
+
+// Restore values read from sources. Should be called under lock
+func (p *Parser) restoreParseState(state parseState) {
+	p.envPrefix = state.envPrefix
+	p.parsedCfg, p.parsedCli, p.args = state.parsedCfg, state.parsedCli, state.args
+	p.parsedEnv, p.parsedFile, p.cfgOrigins = state.parsedEnv, state.parsedFile, state.cfgOrigins
+	p.envSnapshot, p.dotenv = state.envSnapshot, state.dotenv
+	p.warnings = state.warnings
+}
+
+// Fill copy of config struct with parsed values and replace struct with it, so it is not left half-filled
+// if some value is broken. Copy is made from struct state before first parsing, so values removed from sources
+// are reset. Return sorted names of changed configs. Should be called under lock
+func (p *Parser) refill() ([]string, error) {
+	previous, previousSources, previousKeys, previousNilStructs := p.values, p.valueSources, p.valueKeys, p.nilStructs
+	p.values, p.valueSources, p.valueKeys, p.nilStructs = nil, nil, nil, nil
+
+	target := reflect.ValueOf(p.in).Elem()
+	if !p.initial.IsValid() {
+		p.initial = reflect.New(target.Type()).Elem()
+		p.initial.Set(target)
+	}
+	fresh := reflect.New(target.Type())
+	fresh.Elem().Set(p.initial)
+
+	err := p.fillStructWithValues(fresh.Interface(), "")
+	if err == nil {
+		err = p.validate()
+	}
+	if err == nil {
+		err = callValidate(fresh.Elem(), "")
+	}
+	p.emitTrace()
+	if err != nil {
+		p.values, p.valueSources, p.valueKeys, p.nilStructs = previous, previousSources, previousKeys, previousNilStructs
+		return nil, err
+	}
+
+	target.Set(fresh.Elem())
+	for _, handler := range p.onRefill {
+		handler()
+	}
+
+	return diffNames(previous, p.values), nil
+}
+
+// Save raw value written into config struct, its source and key in that source
+func (p *Parser) setValue(name, value, source, key string) {
+	if p.values == nil {
+		p.values = make(map[string]string)
+		p.valueSources = make(map[string]string)
+		p.valueKeys = make(map[string]string)
+	}
+	p.values[name] = value
+	p.valueSources[name] = source
+	p.valueKeys[name] = key
+}
+
+// Return sorted names that are added, removed or changed
+func diffNames(before, after map[string]string) []string {
+	changed := []string{}
+	for name, value := range after {
+		if old, ok := before[name]; !ok || old != value {
+			changed = append(changed, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+
+	return changed
+}
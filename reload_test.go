@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParser_Reload(t *testing.T) {
+	type testStruct struct {
+		Port   int    `config:"name:port;mode:env"`
+		Host   string `config:"name:host;mode:env;default:localhost"`
+		Level  string `config:"name:level;mode:env,cli"`
+		Prefix string `config:"name:prefix;mode:cli;default:app_"`
+	}
+
+	os.Args = []string{"/app/test", "--level=debug"}
+	t.Setenv("APP_PORT", "8080")
+	t.Cleanup(func() { os.Unsetenv("APP_HOST") })
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = p.Reload(); err == nil {
+		t.Errorf("Parser.Reload() should fail before Parse")
+	}
+
+	if err = p.Parse("", "prefix"); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if want := (testStruct{Port: 8080, Host: "localhost", Level: "debug", Prefix: "app_"}); cfg != want {
+		t.Errorf("Parser.Parse() = %v, want %v", cfg, want)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		want    []string
+		wantCfg testStruct
+		wantErr bool
+	}{
+		{name: "not changed", args: []string{"/app/test", "--level=debug"}, want: []string{}, wantCfg: testStruct{Port: 8080, Host: "localhost", Level: "debug", Prefix: "app_"}},
+		{name: "env", args: []string{"/app/test", "--level=debug"}, env: map[string]string{"APP_PORT": "9090", "APP_HOST": "db"}, want: []string{"host", "port"}, wantCfg: testStruct{Port: 9090, Host: "db", Level: "debug", Prefix: "app_"}},
+		{name: "removed", args: []string{"/app/test"}, want: []string{"level"}, wantCfg: testStruct{Port: 9090, Host: "db", Prefix: "app_"}},
+		{name: "broken", args: []string{"/app/test"}, env: map[string]string{"APP_PORT": "ZZZ"}, wantErr: true, wantCfg: testStruct{Port: 9090, Host: "db", Prefix: "app_"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = tt.args
+			for k, v := range tt.env {
+				os.Setenv(k, v) // Should be kept for next cases
+			}
+			got, err := p.Reload()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parser.Reload() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parser.Reload() = %v, want %v", got, tt.want)
+			}
+			if cfg != tt.wantCfg {
+				t.Errorf("Parser.Reload() config = %v, want %v", cfg, tt.wantCfg)
+			}
+		})
+	}
+}
+
+func Test_diffNames(t *testing.T) {
+	got := diffNames(map[string]string{"a": "1", "b": "2", "c": "3"}, map[string]string{"a": "1", "b": "3", "d": "4"})
+	if want := []string{"b", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("diffNames() = %v, want %v", got, want)
+	}
+}
+
+func TestParser_Reload_nilStructs(t *testing.T) {
+	type dbStruct struct {
+		Host string `config:"name:host;required"`
+	}
+	type testStruct struct {
+		Port int       `config:"name:port;mode:env"`
+		DB   *dbStruct `config:"name:db"`
+	}
+
+	os.Args = []string{"/app/test"}
+	t.Setenv("PORT", "8080")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	t.Setenv("PORT", "ZZZ")
+	if _, err = p.Reload(); err == nil {
+		t.Fatalf("Parser.Reload() should fail")
+	}
+	if want := map[string]bool{"DB": true}; !reflect.DeepEqual(p.nilStructs, want) {
+		t.Errorf("Parser.Reload() nilStructs = %v, want %v", p.nilStructs, want)
+	}
+}
+
+func TestParser_Reload_failedKeepsSources(t *testing.T) {
+	type testStruct struct {
+		Port    int    `config:"name:port;mode:cfg"`
+		Host    string `config:"name:host;mode:cfg"`
+		Workers int    `config:"name:workers;mode:env"`
+		Config  string `config:"name:config;mode:cli"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"port": 80}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"/app/test", "--config=" + path}
+
+	var cfg testStruct
+	src := &mutableSource{values: map[string]string{"host": "db"}}
+	p, err := NewParser(&cfg, WithSource(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("config", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	// Reload is rejected because of env, so new file is not applied
+	if err = os.WriteFile(path, []byte(`{"port": 90, "typo": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("WORKERS", "ZZZ")
+	if _, err = p.Reload(); err == nil {
+		t.Fatalf("Parser.Reload() should fail")
+	}
+	if got := p.UnusedKeys(); len(got) != 0 {
+		t.Errorf("Parser.UnusedKeys() = %v after failed reload, want none", got)
+	}
+
+	os.Unsetenv("WORKERS")
+	src.set(map[string]string{"host": "cache"}, nil)
+	p.poll()
+	if want := (testStruct{Port: 80, Host: "cache", Config: path}); cfg != want {
+		t.Errorf("Parser.poll() = %+v, want %+v", cfg, want)
+	}
+	if got := p.Provenance()["port"]; got.Source != sourceCfg || got.Key != "port" {
+		t.Errorf("Parser.Provenance() port = %+v", got)
+	}
+}
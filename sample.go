@@ -0,0 +1,349 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
+
+// Formats of SampleConfig
+const (
+	SampleJSON = "json"
+	SampleYAML = "yaml"
+	SampleTOML = "toml"
+)
+
+// Generators of sample config files. Keys - formats
+var sampleFormats = map[string]func(root *sampleNode) string{
+	SampleJSON: jsonSample,
+	SampleYAML: yamlSample,
+	SampleTOML: tomlSample,
+}
+
+var (
+	yamlPlainKey = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	tomlBareKey  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// Key of sample config file. Node is either value or table of nested keys
+type sampleNode struct {
+	key      string
+	comment  []string    // Lines of comment above key
+	value    interface{} // String, bool, json.Number, []interface{} or map[string]interface{}
+	field    *structField
+	isTable  bool // Nested struct
+	children []*sampleNode
+}
+
+// Return nested table with key, adding it if it is missing
+func (n *sampleNode) table(key string) *sampleNode {
+	for _, child := range n.children {
+		if child.isTable && child.key == key {
+			return child
+		}
+	}
+
+	child := &sampleNode{key: key, isTable: true}
+	n.children = append(n.children, child)
+	return child
+}
+
+// Return sample config file in format (ex.: SampleYAML) with keys of all fields that can be set in config file,
+// except hidden ones. Values are defaults (zero values if default is not set, empty strings for secrets), and
+// descriptions are written as comments (json has no comments, so it has just keys and values)
+func (p *Parser) SampleConfig(format string) (string, error) {
+	generate, ok := sampleFormats[format]
+	if !ok {
+		keys := maps.Keys(sampleFormats)
+		sort.Strings(keys)
+		return "", errors.New(fmt.Sprintf("Unknown sample format %s. Available formats: %s", format, strings.Join(keys, ", ")))
+	}
+
+	return generate(p.sampleTree()), nil
+}
+
+// Build tree of config file keys. Nested names are split by nested separator
+func (p *Parser) sampleTree() *sampleNode {
+	root := &sampleNode{isTable: true}
+	for _, field := range p.sortedFields() {
+		if field.tags.hidden || field.tags.mode != 0 && field.tags.mode&modeCfg == 0 {
+			continue
+		}
+
+		parts := strings.Split(field.tags.cfgName(), p.nestedSeparator())
+		node := root
+		for _, part := range parts[:len(parts)-1] {
+			node = node.table(part)
+		}
+		leaf := p.sampleLeaf(field)
+		leaf.key = parts[len(parts)-1]
+		node.children = append(node.children, leaf)
+	}
+
+	return root
+}
+
+// Make node of field with its default value and description
+func (p *Parser) sampleLeaf(field *structField) *sampleNode {
+	value := field.tags.defaultValue
+	if field.tags.secret {
+		value = ""
+	}
+
+	return &sampleNode{
+		comment: sampleComment(field.tags),
+		value:   sampleValue(value, p.fieldType(field), field.tags),
+		field:   field,
+	}
+}
+
+// Convert raw value of field to value of sample file. Lists are converted to arrays, maps and json encoded values
+// to objects
+func sampleValue(value string, t reflect.Type, tags structFieldTags) interface{} {
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == nil:
+		return value
+	case isTextType(t, tags):
+		return value
+	case tags.encoding == encodingJSON || t.Kind() == reflect.Slice && isNestedStruct(t.Elem()):
+		var decoded interface{}
+		decoder := json.NewDecoder(strings.NewReader(value))
+		decoder.UseNumber()
+		if value != "" && decoder.Decode(&decoded) == nil {
+			return decoded
+		}
+		if t.Kind() == reflect.Slice {
+			return []interface{}{}
+		}
+		return map[string]interface{}{}
+	case t.Kind() == reflect.Slice:
+		items := []interface{}{}
+		for _, item := range tags.splitList(value) {
+			items = append(items, sampleScalar(strings.TrimSpace(item), t.Elem(), tags))
+		}
+		return items
+	case t.Kind() == reflect.Map:
+		items := map[string]interface{}{}
+		for _, item := range tags.splitList(value) {
+			pair := strings.SplitN(item, separatorPair, 2)
+			if len(pair) == 2 {
+				items[strings.TrimSpace(pair[0])] = sampleScalar(strings.TrimSpace(pair[1]), t.Elem(), tags)
+			}
+		}
+		return items
+	}
+
+	return sampleScalar(value, t, tags)
+}
+
+// Check if value of type is written as single string, even if type is slice: types parsed from text (ex.: net.IP)
+// and bytes with encoding tag other than json (ex.: hex)
+func isTextType(t reflect.Type, tags structFieldTags) bool {
+	if tags.encoding != "" {
+		return tags.encoding != encodingJSON
+	}
+
+	return t == ipType || t == ipNetType || t == regexpType.Elem() || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// Convert raw value to value of sample file: numbers and bools are written without quotes, so file
+// has the same types as it would have when written by hand. Values with units and durations are strings
+func sampleScalar(value string, t reflect.Type, tags structFieldTags) interface{} {
+	if tags.unit != "" || t == durationType {
+		return value
+	}
+
+	switch {
+	case t.Kind() == reflect.Bool:
+		if value == "" {
+			return false
+		}
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case isNumeric(t.Kind()) && t.Kind() != reflect.Complex64 && t.Kind() != reflect.Complex128:
+		if value == "" {
+			return json.Number("0")
+		}
+		if _, err := strconv.ParseFloat(value, 64); err == nil && json.Valid([]byte(value)) {
+			return json.Number(value)
+		}
+	}
+
+	return value
+}
+
+// Return comment lines of field: description with hints, example, required mark and long description
+func sampleComment(tags structFieldTags) []string {
+	first := describe(tags)
+	if tags.example != "" {
+		first = strings.TrimSpace(fmt.Sprintf("%s Ex.: %s", first, tags.example))
+	}
+	if tags.required {
+		first = strings.TrimSpace(first + " (required)")
+	}
+
+	result := []string{}
+	if first != "" {
+		result = append(result, first)
+	}
+	if tags.longDescription != "" {
+		result = append(result, strings.Split(tags.longDescription, "\n")...)
+	}
+
+	return result
+}
+
+// Encode value as json without escaping of html chars. Json values are valid yaml flow values too
+func sampleJSON(value interface{}) string {
+	buffer := bytes.NewBufferString("")
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// Generate json sample
+func jsonSample(root *sampleNode) string {
+	buffer := bytes.NewBufferString("")
+	writeJSONSample(buffer, root, "")
+	buffer.WriteString("\n")
+
+	return buffer.String()
+}
+
+func writeJSONSample(buffer *bytes.Buffer, node *sampleNode, indent string) {
+	if len(node.children) == 0 {
+		buffer.WriteString("{}")
+		return
+	}
+
+	buffer.WriteString("{\n")
+	for i, child := range node.children {
+		buffer.WriteString(fmt.Sprintf("%s  %s: ", indent, sampleJSON(child.key)))
+		if child.isTable {
+			writeJSONSample(buffer, child, indent+"  ")
+		} else {
+			buffer.WriteString(sampleJSON(child.value))
+		}
+		if i < len(node.children)-1 {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString("\n")
+	}
+	buffer.WriteString(indent + "}")
+}
+
+// Generate yaml sample. Values are written in flow style
+func yamlSample(root *sampleNode) string {
+	buffer := bytes.NewBufferString("")
+	writeYAMLSample(buffer, root, "")
+
+	return buffer.String()
+}
+
+func writeYAMLSample(buffer *bytes.Buffer, node *sampleNode, indent string) {
+	for _, child := range node.children {
+		for _, line := range child.comment {
+			buffer.WriteString(fmt.Sprintf("%s# %s\n", indent, line))
+		}
+
+		key := child.key
+		if !yamlPlainKey.MatchString(key) {
+			key = sampleJSON(key)
+		}
+		if child.isTable {
+			buffer.WriteString(fmt.Sprintf("%s%s:\n", indent, key))
+			writeYAMLSample(buffer, child, indent+"  ")
+		} else {
+			buffer.WriteString(fmt.Sprintf("%s%s: %s\n", indent, key, sampleJSON(child.value)))
+		}
+	}
+}
+
+// Generate toml sample. Keys of table are written before nested tables, as toml requires
+func tomlSample(root *sampleNode) string {
+	buffer := bytes.NewBufferString("")
+	writeTOMLSample(buffer, root, nil)
+
+	return strings.TrimPrefix(buffer.String(), "\n")
+}
+
+func writeTOMLSample(buffer *bytes.Buffer, node *sampleNode, path []string) {
+	hasValues := false
+	for _, child := range node.children {
+		hasValues = hasValues || !child.isTable
+	}
+	if len(path) > 0 && hasValues {
+		buffer.WriteString(fmt.Sprintf("\n%s[%s]\n", tomlComment(node.comment), strings.Join(path, ".")))
+	}
+
+	for _, child := range node.children {
+		if !child.isTable {
+			buffer.WriteString(fmt.Sprintf("%s%s = %s\n", tomlComment(child.comment), tomlKey(child.key), tomlValue(child.value)))
+		}
+	}
+	for _, child := range node.children {
+		if child.isTable {
+			writeTOMLSample(buffer, child, append(append([]string{}, path...), tomlKey(child.key)))
+		}
+	}
+}
+
+func tomlComment(lines []string) string {
+	result := ""
+	for _, line := range lines {
+		result += "# " + line + "\n"
+	}
+
+	return result
+}
+
+// Return key as bare key, or quoted if it has other chars
+func tomlKey(key string) string {
+	if tomlBareKey.MatchString(key) {
+		return key
+	}
+
+	return sampleJSON(key)
+}
+
+// Encode value in toml. Objects are written as inline tables with sorted keys
+func tomlValue(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		items := []string{}
+		for _, item := range v {
+			items = append(items, tomlValue(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		keys := maps.Keys(v)
+		sort.Strings(keys)
+		items := []string{}
+		for _, key := range keys {
+			items = append(items, fmt.Sprintf("%s = %s", tomlKey(key), tomlValue(v[key])))
+		}
+		if len(items) == 0 {
+			return "{}"
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	case nil:
+		return `""` // Toml has no null
+	default:
+		return sampleJSON(v)
+	}
+}
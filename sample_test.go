@@ -0,0 +1,162 @@
+package config
+
+import (
+	"encoding/hex"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParser_SampleConfig(t *testing.T) {
+	type dbStruct struct {
+		Host     string `config:"name:host;default:localhost;desc:Database host"`
+		Password string `config:"name:password;default:qwerty;secret"`
+	}
+	type testStruct struct {
+		Port    int               `config:"name:port;default:8080;desc:Port to listen;example:80"`
+		Debug   bool              `config:"name:debug"`
+		Timeout time.Duration     `config:"name:timeout;default:30s"`
+		Tags    []string          `config:"name:tags;default:a,b"`
+		Labels  map[string]string `config:"name:labels;default:env=prod"`
+		Token   string            `config:"name:token;mode:cli"`
+		Debt    string            `config:"name:debt;hidden"`
+		Name    string            `config:"name:name;required;longdesc:Shown in logs"`
+		DB      dbStruct          `config:"name:db"`
+	}
+
+	os.Args = []string{"/app/test"}
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "json",
+			format: SampleJSON,
+			want: `{
+  "db": {
+    "host": "localhost",
+    "password": ""
+  },
+  "debug": false,
+  "labels": {"env":"prod"},
+  "name": "",
+  "port": 8080,
+  "tags": ["a","b"],
+  "timeout": "30s"
+}
+`,
+		},
+		{
+			name:   "yaml",
+			format: SampleYAML,
+			want: `db:
+  # Database host
+  host: "localhost"
+  password: ""
+debug: false
+labels: {"env":"prod"}
+# (required)
+# Shown in logs
+name: ""
+# Port to listen Ex.: 80
+port: 8080
+tags: ["a","b"]
+timeout: "30s"
+`,
+		},
+		{
+			name:   "toml",
+			format: SampleTOML,
+			want: `debug = false
+labels = { env = "prod" }
+# (required)
+# Shown in logs
+name = ""
+# Port to listen Ex.: 80
+port = 8080
+tags = ["a", "b"]
+timeout = "30s"
+
+[db]
+# Database host
+host = "localhost"
+password = ""
+`,
+		},
+		{
+			name:    "unknown",
+			format:  "ini",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.SampleConfig(tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parser.SampleConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parser.SampleConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_SampleConfig_parsable(t *testing.T) {
+	type testStruct struct {
+		Port    int      `config:"name:port;default:8080"`
+		Servers []string `config:"name:servers;default:a b;sep: "`
+		IP      net.IP   `config:"name:ip;default:10.0.0.1"`
+		Key     []byte   `config:"name:key;default:deadbeef;encoding:hex"`
+		Gateway net.IP   `config:"name:gateway"`
+		Secret  []byte   `config:"name:secret;encoding:hex"`
+		Config  string   `config:"name:config;mode:cli"`
+	}
+
+	os.Args = []string{"/app/test"}
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample, err := p.SampleConfig(SampleJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{`"ip": "10.0.0.1"`, `"key": "deadbeef"`, `"gateway": ""`, `"secret": ""`} {
+		if !strings.Contains(sample, want) {
+			t.Errorf("Parser.SampleConfig() = %s, want it to contain %s", sample, want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err = os.WriteFile(path, []byte(sample), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"/app/test", "--config=" + path}
+	var parsed testStruct
+	p, _ = NewParser(&parsed)
+	if err = p.Parse("config", ""); err != nil {
+		t.Fatalf("Parser.Parse() of sample %s error = %v", sample, err)
+	}
+	if parsed.Port != 8080 || len(parsed.Servers) != 2 || parsed.IP.String() != "10.0.0.1" || hex.EncodeToString(parsed.Key) != "deadbeef" {
+		t.Errorf("Parser.Parse() of sample = %+v", parsed)
+	}
+	for name, info := range p.Provenance() {
+		if name != "config" && info.Source != sourceCfg {
+			t.Errorf("Parser.Parse() took %s from %s, want it from sample file", name, info.Source)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Write values of config struct, changed since last Parse or Reload, into config file at path. Format should be
+// "json", as the only format of config files that can be parsed. Other keys of existing file (including unknown ones)
+// are kept, and values of not changed fields are not written, so values from env, command line or defaults don't get
+// into the file. File is replaced atomically, keeping its permissions
+func (p *Parser) Save(path, format string) error {
+	if format != SampleJSON {
+		return errors.New(fmt.Sprintf("Unsupported save format %s. Config can be saved just as %s", format, SampleJSON))
+	}
+
+	content := make(map[string]interface{})
+	perm := os.FileMode(0644)
+	original, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil:
+		decoder := json.NewDecoder(bytes.NewReader(original))
+		decoder.UseNumber() // Numbers of unknown keys are kept as they are
+		err = decoder.Decode(&content)
+		if err != nil {
+			return fmt.Errorf("Cannot parse %s: %w", path, err)
+		}
+		if info, statErr := os.Stat(path); statErr == nil {
+			perm = info.Mode().Perm()
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	section, err := p.sectionToSave(content)
+	if err != nil {
+		return err
+	}
+	for _, field := range p.sortedFields() {
+		if field.tags.mode != 0 && field.tags.mode&modeCfg == 0 {
+			continue
+		}
+		value, ok := p.fieldValue(field)
+		if !ok || !p.isChanged(field, value) {
+			continue
+		}
+
+		saved, err := savedValue(value, field.tags)
+		if err != nil {
+			return err
+		}
+		for _, name := range append([]string{field.tags.cfgName()}, field.tags.aliases...) {
+			p.deleteNested(section, strings.Split(name, p.nestedSeparator()))
+		}
+		setNested(section, strings.Split(field.tags.cfgName(), p.nestedSeparator()), saved)
+	}
+
+	buffer := bytes.NewBufferString("")
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(content)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, buffer.Bytes(), perm)
+}
+
+// Return object of config section set by WithConfigSection, adding missing objects of its path
+func (p *Parser) sectionToSave(content map[string]interface{}) (map[string]interface{}, error) {
+	if p.cfgSection == "" {
+		return content, nil
+	}
+
+	for _, key := range strings.Split(p.cfgSection, p.nestedSeparator()) {
+		value, ok := content[key]
+		if !ok {
+			value = make(map[string]interface{})
+			content[key] = value
+		}
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			return nil, errors.New(fmt.Sprintf("Config section %s should be an object", p.cfgSection))
+		}
+		content = object
+	}
+
+	return content, nil
+}
+
+// Delete key of nested objects by path. Keys are compared after normalization
+func (p *Parser) deleteNested(object map[string]interface{}, path []string) {
+	for key, value := range object {
+		if !p.sameKey(key, path[0]) {
+			continue
+		}
+		if len(path) == 1 {
+			delete(object, key)
+		} else if child, ok := value.(map[string]interface{}); ok {
+			p.deleteNested(child, path[1:])
+		}
+	}
+}
+
+// Return value of struct field. Return false if it is inside of nil struct pointer
+func (p *Parser) fieldValue(field *structField) (reflect.Value, bool) {
+	v := reflect.ValueOf(p.in).Elem()
+	for _, name := range strings.Split(field.name, separatorNested) {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.FieldByName(name)
+		if !v.IsValid() {
+			return reflect.Value{}, false
+		}
+	}
+
+	return v, true
+}
+
+// Check if value of field differs from value put into it by last Parse or Reload
+func (p *Parser) isChanged(field *structField, value reflect.Value) bool {
+	if p.mu != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+	}
+
+	parsed := reflect.New(value.Type()).Elem()
+	if raw, ok := p.values[field.tags.name]; ok {
+		tags := p.sourceTags(field.tags, p.valueSources[field.tags.name])
+		if err := p.writeValueToField(parsed, raw, tags); err != nil {
+			return true
+		}
+	}
+
+	return !reflect.DeepEqual(parsed.Interface(), value.Interface())
+}
+
+// Convert value of field into json value, that is parsed back into the same value
+func savedValue(v reflect.Value, tags structFieldTags) (interface{}, error) {
+	switch tags.encoding {
+	case encodingJSON:
+		return v.Interface(), nil
+	case encodingHex:
+		content := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(content), v)
+		return hex.EncodeToString(content), nil
+	}
+
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String(), nil
+	case tags.unit != "" && isNumeric(v.Kind()):
+		if v.CanFloat() {
+			return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+		}
+		return fmt.Sprint(v.Interface()), nil
+	case v.Type() == ipType:
+		if v.Len() == 0 {
+			return "", nil
+		}
+		return v.Interface().(net.IP).String(), nil
+	case v.Type() == ipNetType:
+		ipNet := v.Interface().(net.IPNet)
+		if ipNet.IP == nil {
+			return "", nil
+		}
+		return ipNet.String(), nil
+	case v.Type() == reflect.PointerTo(ipNetType) || v.Type() == regexpType:
+		if v.IsNil() {
+			return "", nil
+		}
+		return v.Interface().(fmt.Stringer).String(), nil
+	}
+
+	marshaler, ok := v.Interface().(encoding.TextMarshaler)
+	if !ok && v.CanAddr() {
+		marshaler, ok = v.Addr().Interface().(encoding.TextMarshaler)
+	}
+	if ok {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return "", nil
+		}
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	switch v.Kind() {
+	case reflect.Bool, reflect.String:
+		return v.Interface(), nil
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprint(v.Interface()), nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && isNestedStruct(v.Type().Elem()) {
+			return savedStructs(v)
+		}
+		items := []interface{}{}
+		for i := 0; i < v.Len(); i++ {
+			item, err := savedValue(v.Index(i), tags)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case reflect.Map:
+		items := map[string]interface{}{}
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := savedValue(iter.Key(), tags)
+			if err != nil {
+				return nil, err
+			}
+			item, err := savedValue(iter.Value(), tags)
+			if err != nil {
+				return nil, err
+			}
+			items[fmt.Sprint(key)] = item
+		}
+		return items, nil
+	}
+	if isNumeric(v.Kind()) {
+		return v.Interface(), nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("%s is not supported", v.Type().String()))
+}
+
+// Convert slice of structs into json array of objects, with keys of fields tags of struct
+func savedStructs(v reflect.Value) (interface{}, error) {
+	items := []interface{}{}
+	for i := 0; i < v.Len(); i++ {
+		elem := reflect.New(v.Type().Elem())
+		elem.Elem().Set(v.Index(i))
+		itemParser, err := NewParser(elem.Interface())
+		if err != nil {
+			return nil, err
+		}
+
+		item := make(map[string]interface{})
+		for _, field := range itemParser.fields {
+			value, ok := itemParser.fieldValue(field)
+			if !ok {
+				continue
+			}
+			saved, err := savedValue(value, field.tags)
+			if err != nil {
+				return nil, err
+			}
+			setNested(item, strings.Split(field.tags.cfgName(), itemParser.nestedSeparator()), saved)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParser_Save(t *testing.T) {
+	type server struct {
+		Host string `config:"name:host"`
+		Port int    `config:"name:port"`
+	}
+	type testStruct struct {
+		Port    int           `config:"name:port"`
+		Host    string        `config:"name:host;alias:hostname"`
+		Level   string        `config:"name:level;default:info"`
+		Timeout time.Duration `config:"name:timeout"`
+		Size    int64         `config:"name:size;unit:bytes"`
+		Tags    []string      `config:"name:tags"`
+		Servers []server      `config:"name:servers"`
+		Token   string        `config:"name:token;mode:cli"`
+		Config  string        `config:"name:config"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	original := `{"app": {"port": 8080, "hostname": "old", "timeout": "1m", "extra": {"keep": 1.50}}, "other": true}`
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"/app/test", "--token=abc", "--config=" + path}
+	t.Setenv("LEVEL", "debug")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg, WithConfigSection("app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("config", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	cfg.Host = "new"
+	cfg.Size = 2000000
+	cfg.Tags = []string{"a", "b"}
+	cfg.Servers = []server{{Host: "db", Port: 5432}}
+	cfg.Token = "changed"
+	if err = p.Save(path, "json"); err != nil {
+		t.Fatalf("Parser.Save() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	want := `{
+  "app": {
+    "extra": {
+      "keep": 1.50
+    },
+    "host": "new",
+    "port": 8080,
+    "servers": [
+      {
+        "host": "db",
+        "port": 5432
+      }
+    ],
+    "size": "2000000",
+    "tags": [
+      "a",
+      "b"
+    ],
+    "timeout": "1m"
+  },
+  "other": true
+}
+`
+	if string(content) != want {
+		t.Errorf("Parser.Save() wrote %s, want %s", content, want)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Parser.Save() changed permissions to %v", info.Mode().Perm())
+	}
+
+	os.Args = []string{"/app/test", "--config=" + path}
+	var saved testStruct
+	p, _ = NewParser(&saved, WithConfigSection("app"))
+	if err = p.Parse("config", ""); err != nil {
+		t.Fatalf("Parser.Parse() of saved file error = %v", err)
+	}
+	cfg.Token = ""
+	if !reflect.DeepEqual(saved, cfg) {
+		t.Errorf("Parser.Parse() of saved file = %+v, want %+v", saved, cfg)
+	}
+
+	err = p.Save(path, "yaml")
+	if err == nil || !strings.Contains(err.Error(), "Unsupported save format yaml") {
+		t.Errorf("Parser.Save() error = %v, want unsupported format", err)
+	}
+}
+
+func TestParser_Save_envListSeparator(t *testing.T) {
+	type testStruct struct {
+		Path   []string `config:"name:path"`
+		Hosts  []string `config:"name:hosts"`
+		Level  string   `config:"name:level"`
+		Config string   `config:"name:config"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"/app/test", "--config=" + path}
+	t.Setenv("PATH", "a:b")
+	t.Setenv("HOSTS_0", "x")
+	t.Setenv("HOSTS_1", "y")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg, WithEnvListSeparator(':'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("config", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	cfg.Level = "debug"
+	if err = p.Save(path, "json"); err != nil {
+		t.Fatalf("Parser.Save() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	want := `{
+  "level": "debug"
+}
+`
+	if string(content) != want {
+		t.Errorf("Parser.Save() wrote %s, want %s", content, want)
+	}
+}
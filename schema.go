@@ -0,0 +1,145 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Dialect of schema made by JSONSchema
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Return JSON Schema (draft 2020-12) of config file: keys of all fields that can be set in config file (except
+// hidden ones) with their types, defaults, allowed values, limits and descriptions. Field is required in schema
+// just if it is required and can be set only in config file, because other required fields can come from env
+// or command line. Unknown keys are allowed, as they are in Parse
+func (p *Parser) JSONSchema() ([]byte, error) {
+	schema := p.objectSchema(p.sampleTree())
+	schema["$schema"] = schemaDialect
+
+	buffer := bytes.NewBufferString("")
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Make schema of object with keys of table node
+func (p *Parser) objectSchema(node *sampleNode) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, child := range node.children {
+		if child.isTable {
+			properties[child.key] = p.objectSchema(child)
+			continue
+		}
+
+		properties[child.key] = p.fieldSchema(child.field)
+		if child.field.tags.required && child.field.tags.mode == modeCfg {
+			required = append(required, child.key)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// Make schema of field value
+func (p *Parser) fieldSchema(field *structField) map[string]interface{} {
+	tags := field.tags
+	t := p.fieldType(field)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	schema := typeSchema(t, tags)
+
+	description := strings.TrimSpace(tags.description + "\n\n" + tags.longDescription)
+	if description != "" {
+		schema["description"] = description
+	}
+	if tags.hasDefaultValue && !tags.secret {
+		schema["default"] = sampleValue(tags.defaultValue, t, tags)
+	}
+	if tags.example != "" {
+		schema["examples"] = []interface{}{sampleValue(tags.example, t, tags)}
+	}
+	if tags.isDeprecated {
+		schema["deprecated"] = true
+	}
+	if tags.secret {
+		schema["writeOnly"] = true
+	}
+
+	if len(tags.oneOf) > 0 && t != nil {
+		target := schema
+		itemType := t
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			target = items
+			itemType = t.Elem()
+		}
+		enum := []interface{}{}
+		for _, choice := range tags.oneOf {
+			enum = append(enum, sampleScalar(choice, itemType, tags))
+		}
+		target["enum"] = enum
+	}
+
+	if t != nil && tags.unit == "" && t != durationType && !isTextType(t, tags) {
+		limits := map[reflect.Kind][2]string{
+			reflect.String: {"minLength", "maxLength"},
+			reflect.Slice:  {"minItems", "maxItems"},
+			reflect.Map:    {"minProperties", "maxProperties"},
+		}
+		names, ok := limits[t.Kind()]
+		if isNumeric(t.Kind()) {
+			names, ok = [2]string{"minimum", "maximum"}, true
+		}
+		if ok && tags.hasMin {
+			schema[names[0]] = tags.min
+		}
+		if ok && tags.hasMax {
+			schema[names[1]] = tags.max
+		}
+	}
+
+	return schema
+}
+
+// Make schema of type, as its values are written in config file. Durations, values with units, types parsed
+// from text (ex.: net.IP) and encoded bytes are strings
+func typeSchema(t reflect.Type, tags structFieldTags) map[string]interface{} {
+	if t == nil || tags.unit != "" || t == durationType || isTextType(t, tags) {
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch {
+	case t.Kind() == reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case isInteger(t.Kind()):
+		return map[string]interface{}{"type": "integer"}
+	case isNumeric(t.Kind()):
+		return map[string]interface{}{"type": "number"}
+	case t.Kind() == reflect.Slice && isNestedStruct(t.Elem()):
+		return map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "object"}}
+	case t.Kind() == reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), tags)}
+	case t.Kind() == reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), tags)}
+	case t.Kind() == reflect.Struct && tags.encoding == encodingJSON:
+		return map[string]interface{}{"type": "object"}
+	case t.Kind() == reflect.Interface:
+		return map[string]interface{}{} // Any value
+	}
+
+	return map[string]interface{}{"type": "string"}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParser_JSONSchema(t *testing.T) {
+	type dbStruct struct {
+		Host     string `config:"name:host;default:localhost;desc:Database host"`
+		Password string `config:"name:password;default:qwerty;secret"`
+	}
+	type testStruct struct {
+		Port    int            `config:"name:port;default:8080;min:1;max:65535;example:80"`
+		Level   string         `config:"name:level;oneof:debug,info;deprecated"`
+		Ratio   float64        `config:"name:ratio"`
+		Timeout time.Duration  `config:"name:timeout;default:30s"`
+		Tags    []string       `config:"name:tags;default:a,b;max:3"`
+		Limits  map[string]int `config:"name:limits"`
+		Name    string         `config:"name:name;required;mode:cfg"`
+		Token   string         `config:"name:token;mode:cli"`
+		Key     string         `config:"name:key;required"`
+		IP      net.IP         `config:"name:ip;default:10.0.0.1"`
+		Allow   []net.IP       `config:"name:allow;default:10.0.0.1,10.0.0.2"`
+		Cert    []byte         `config:"name:cert;default:deadbeef;encoding:hex;max:4"`
+		DB      dbStruct       `config:"name:db"`
+	}
+
+	os.Args = []string{"/app/test"}
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := p.JSONSchema()
+	if err != nil {
+		t.Fatalf("Parser.JSONSchema() error = %v", err)
+	}
+	want := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"db": {
+				"type": "object",
+				"properties": {
+					"host": {"type": "string", "default": "localhost", "description": "Database host"},
+					"password": {"type": "string", "writeOnly": true}
+				}
+			},
+			"level": {"type": "string", "enum": ["debug", "info"], "deprecated": true},
+			"key": {"type": "string"},
+			"ip": {"type": "string", "default": "10.0.0.1"},
+			"allow": {"type": "array", "items": {"type": "string"}, "default": ["10.0.0.1", "10.0.0.2"]},
+			"cert": {"type": "string", "default": "deadbeef"},
+			"limits": {"type": "object", "additionalProperties": {"type": "integer"}},
+			"name": {"type": "string"},
+			"port": {"type": "integer", "default": 8080, "examples": [80], "minimum": 1, "maximum": 65535},
+			"ratio": {"type": "number"},
+			"tags": {"type": "array", "items": {"type": "string"}, "default": ["a", "b"], "maxItems": 3},
+			"timeout": {"type": "string", "default": "30s"}
+		},
+		"required": ["name"]
+	}`
+
+	var gotSchema, wantSchema interface{}
+	if err = json.Unmarshal(got, &gotSchema); err != nil {
+		t.Fatalf("Parser.JSONSchema() is not valid json: %v", err)
+	}
+	_ = json.Unmarshal([]byte(want), &wantSchema)
+	if !reflect.DeepEqual(gotSchema, wantSchema) {
+		t.Errorf("Parser.JSONSchema() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParser_secret(t *testing.T) {
+	type testStruct struct {
+		Token string `config:"name:token;mode:cli;secret;default:dev-token;desc:API token"`
+		Port  int    `config:"name:port;mode:cli;secret"`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := p.Help(""); strings.Contains(got, "dev-token") || !strings.Contains(got, "--token[=****]") {
+		t.Errorf("Parser.Help() = %v, should redact default value", got)
+	}
+
+	p.parsedCli = map[string]string{"port": "s3cr3t"}
+	err = p.fillStructWithValues(&cfg, "")
+	if err == nil {
+		t.Fatalf("Parser.fillStructWithValues() should fail")
+	}
+	if strings.Contains(err.Error(), "s3cr3t") {
+		t.Errorf("Parser.fillStructWithValues() error = %v, should not contain secret value", err)
+	}
+}
@@ -0,0 +1,308 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// External provider of config values (Consul, etcd, etc.).
+// Loaded values are used as config file ones, so they are available only for fields with cfg mode
+type Source interface {
+	// Short name of source. Used in error messages
+	Name() string
+	// Load all values of source. Nested keys should be separated with "."
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+// Source that is able to notify about changes of its values. Parser watches it if WithWatch is set
+type WatchableSource interface {
+	Source
+	// Block until ctx is done or watching is broken, calling onChange after each change of values
+	Watch(ctx context.Context, onChange func()) error
+}
+
+// Source that combines other sources (ex.: ChainSource), and reports which of them provided each value.
+// Wrappers of sources (ex.: CachedSource) should implement it too, forwarding origins of wrapped source
+type OriginSource interface {
+	Source
+	// Return name of source that provided config name with last Load
+	Origin(name string) (string, bool)
+}
+
+// Key of context value with function that loads single source with settings of parser (timeout, retries)
+type sourceLoaderKey struct{}
+
+// Key of context value with function that reports warning of source loading to parser
+type sourceWarningKey struct{}
+
+// Report warning of source loading (ex.: failed member of chain) to parser, set in ctx by parser.
+// Warning is dropped if ctx has no parser
+func reportSourceWarning(ctx context.Context, warning error) {
+	if report, ok := ctx.Value(sourceWarningKey{}).(func(error)); ok {
+		report(warning)
+	}
+}
+
+// Load single source with settings of parser, set in ctx by parser. Source is loaded directly if ctx has no parser settings.
+// Errors are prefixed with name of source
+func loadWithSettings(ctx context.Context, src Source) (map[string]string, error) {
+	if loader, ok := ctx.Value(sourceLoaderKey{}).(func(context.Context, Source) (map[string]string, error)); ok {
+		return loader(ctx, src)
+	}
+
+	values, err := src.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", src.Name(), err)
+	}
+	return values, nil
+}
+
+// Maximal delay between retries of source loading
+const maxRetryBackoff = 30 * time.Second
+
+// Retry settings of source, set with WithSourceRetry
+type retryPolicy struct {
+	retries int
+	backoff time.Duration
+}
+
+// Error of source that was not loaded in time, set with WithSourceTimeout
+type SourceTimeoutError struct {
+	Source  string        // Name of source
+	Timeout time.Duration // Exceeded timeout
+}
+
+func (e *SourceTimeoutError) Error() string {
+	return fmt.Sprintf("%s: loading is not finished in %s", e.Source, e.Timeout)
+}
+
+// Add external source of config values.
+// Sources are loaded after config file in order they were added, and override its values
+func WithSource(src Source) Option {
+	return func(p *Parser) {
+		p.sources = append(p.sources, src)
+	}
+}
+
+// Limit loading time of source with specific name (ex.: "consul").
+// If source is not loaded in time, Parse returns *SourceTimeoutError
+func WithSourceTimeout(name string, timeout time.Duration) Option {
+	return func(p *Parser) {
+		if p.sourceTimeouts == nil {
+			p.sourceTimeouts = make(map[string]time.Duration)
+		}
+		p.sourceTimeouts[name] = timeout
+	}
+}
+
+// Retry failed loading of source with specific name up to retries times.
+// Delay before first retry is backoff, and it is doubled for each next one (up to 30 seconds). Random jitter is applied to delays
+func WithSourceRetry(name string, retries int, backoff time.Duration) Option {
+	return func(p *Parser) {
+		if p.sourceRetries == nil {
+			p.sourceRetries = make(map[string]retryPolicy)
+		}
+		p.sourceRetries[name] = retryPolicy{retries: retries, backoff: backoff}
+	}
+}
+
+// Don't fail parsing if listed sources (or any source, if names are not set) can't be loaded.
+// Their errors are reported as warnings (see Warnings and WithWarningHandler), and other sources and defaults are used
+func WithLenientSources(names ...string) Option {
+	return func(p *Parser) {
+		p.lenientSources = true
+		if len(names) == 0 {
+			return
+		}
+		if p.lenientSourceNames == nil {
+			p.lenientSourceNames = make(map[string]bool)
+		}
+		for _, name := range names {
+			p.lenientSourceNames[name] = true
+		}
+	}
+}
+
+// Check if source errors should not fail parsing
+func (p *Parser) isLenientSource(name string) bool {
+	return p.lenientSources && (len(p.lenientSourceNames) == 0 || p.lenientSourceNames[name])
+}
+
+// Load values from all external sources into parsed config values
+func (p *Parser) loadSources() error {
+	if len(p.sources) == 0 {
+		return nil
+	}
+
+	if p.parsedCfg == nil {
+		p.parsedCfg = make(map[string]string)
+	}
+
+	values, origins, warnings, err := p.fetchSources(false)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range warnings {
+		p.warn(warning)
+	}
+
+	for k, v := range values {
+		p.parsedCfg[k] = v
+	}
+	p.cfgOrigins = origins
+
+	return nil
+}
+
+// Fetch all external sources concurrently, and merge their values in order sources were added.
+// Return also names of sources that provided each value.
+// Errors of lenient sources are returned as warnings, unless strict is set
+func (p *Parser) fetchSources(strict bool) (map[string]string, map[string]string, []error, error) {
+	results := make([]map[string]string, len(p.sources))
+	warnings := make([][]error, len(p.sources))
+	var warningsMu sync.Mutex
+	g, ctx := errgroup.WithContext(context.Background())
+	ctx = context.WithValue(ctx, sourceLoaderKey{}, p.loadSourceWithRetry) // Members of combined sources are loaded with their settings
+	for i, src := range p.sources {
+		i, src := i, src
+		g.Go(func() error {
+			srcCtx := context.WithValue(ctx, sourceWarningKey{}, func(warning error) { // Ex.: failed members of chain
+				warningsMu.Lock()
+				warnings[i] = append(warnings[i], warning)
+				warningsMu.Unlock()
+			})
+			values, warning, err := p.loadSourceWithFallback(srcCtx, src)
+			warningsMu.Lock()
+			defer warningsMu.Unlock()
+			if err != nil && !strict && p.isLenientSource(src.Name()) {
+				warnings[i] = append(warnings[i], err)
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			results[i] = values
+			if warning != nil {
+				warnings[i] = append(warnings[i], warning)
+			}
+			return nil
+		})
+	}
+
+	err := g.Wait()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	merged := make(map[string]string)
+	origins := make(map[string]string)
+	for i, values := range results {
+		combined, isCombined := p.sources[i].(OriginSource)
+		for k, v := range values {
+			key := strings.ReplaceAll(k, separatorNested, p.nestedSeparator())
+			merged[key] = v
+			origins[key] = p.sources[i].Name()
+			if isCombined {
+				if origin, ok := combined.Origin(k); ok {
+					origins[key] = origin
+				}
+			}
+		}
+	}
+
+	// Warnings are collected here, so handler is not called concurrently
+	result := []error{}
+	for _, sourceWarnings := range warnings {
+		result = append(result, sourceWarnings...)
+	}
+
+	return merged, origins, result, nil
+}
+
+// Load values of single source, retrying failed attempts with exponential backoff if it is set for the source
+func (p *Parser) loadSourceWithRetry(ctx context.Context, src Source) (map[string]string, error) {
+	policy := p.sourceRetries[src.Name()]
+	delay := policy.backoff
+	for attempt := 0; ; attempt++ {
+		values, err := p.loadSource(ctx, src)
+		if err == nil || attempt >= policy.retries {
+			return values, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(withJitter(delay)):
+		}
+
+		delay *= 2
+		if delay > maxRetryBackoff {
+			delay = maxRetryBackoff
+		}
+	}
+}
+
+// Load values of single source, limiting its time if timeout is set for it
+func (p *Parser) loadSource(ctx context.Context, src Source) (map[string]string, error) {
+	timeout, ok := p.sourceTimeouts[src.Name()]
+	if !ok {
+		values, err := src.Load(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", src.Name(), err)
+		}
+		return values, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		values map[string]string
+		err    error
+	}
+	// Load in separate goroutine, so source that ignores ctx can't hang parsing
+	done := make(chan result, 1)
+	go func() {
+		values, err := src.Load(ctx)
+		done <- result{values: values, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err == nil {
+			return r.values, nil
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, &SourceTimeoutError{Source: src.Name(), Timeout: timeout}
+		}
+		return nil, fmt.Errorf("%s: %w", src.Name(), r.err)
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, &SourceTimeoutError{Source: src.Name(), Timeout: timeout}
+		}
+		return nil, fmt.Errorf("%s: %w", src.Name(), ctx.Err())
+	}
+}
+
+// Randomize delay in range [delay/2, delay], so many instances don't retry simultaneously
+func withJitter(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// Convert key of hierarchical storage into config name. Ex.: key "app/db/host" with prefix "app/" gives "db.host"
+func keyFromPath(key, prefix string) string {
+	name := strings.Trim(strings.TrimPrefix(key, prefix), "/")
+	return strings.ReplaceAll(name, "/", separatorNested)
+}
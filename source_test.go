@@ -0,0 +1,294 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Source with predefined values for tests
+type staticSource struct {
+	name   string
+	values map[string]string
+	err    error
+	delay  time.Duration
+}
+
+func (s *staticSource) Name() string {
+	return s.name
+}
+
+func (s *staticSource) Load(ctx context.Context) (map[string]string, error) {
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return s.values, s.err
+}
+
+func TestParser_loadSources(t *testing.T) {
+	tests := []struct {
+		name      string
+		parsedCfg map[string]string
+		sources   []Source
+		want      map[string]string
+		wantErr   bool
+	}{
+		{name: "no sources", parsedCfg: nil, want: nil},
+		{
+			name:      "override file",
+			parsedCfg: map[string]string{"a": "file", "b": "file"},
+			sources: []Source{
+				&staticSource{name: "one", values: map[string]string{"b": "one", "c": "one"}},
+				&staticSource{name: "two", values: map[string]string{"c": "two"}},
+			},
+			want: map[string]string{"a": "file", "b": "one", "c": "two"},
+		},
+		{
+			name:    "without file",
+			sources: []Source{&staticSource{name: "one", values: map[string]string{"a": "one"}}},
+			want:    map[string]string{"a": "one"},
+		},
+		{
+			name:    "error",
+			sources: []Source{&staticSource{name: "one", err: errors.New("unavailable")}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{parsedCfg: tt.parsedCfg}
+			for _, src := range tt.sources {
+				WithSource(src)(p)
+			}
+			err := p.loadSources()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parser.loadSources() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(tt.want, p.parsedCfg) {
+				t.Errorf("Parser.loadSources() = %v, want %v", p.parsedCfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_loadSourcesConcurrently(t *testing.T) {
+	p := &Parser{}
+	for i := 0; i < 5; i++ {
+		WithSource(&staticSource{name: "slow", values: map[string]string{"a": "slow"}, delay: 100 * time.Millisecond})(p)
+	}
+
+	start := time.Now()
+	if err := p.loadSources(); err != nil {
+		t.Fatalf("Parser.loadSources() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("Parser.loadSources() took %v, sources should be loaded concurrently", elapsed)
+	}
+
+	// Failed source should cancel loading of others
+	p = &Parser{}
+	WithSource(&staticSource{name: "hanging", delay: time.Hour})(p)
+	WithSource(&staticSource{name: "broken", err: errors.New("unavailable")})(p)
+	start = time.Now()
+	if err := p.loadSources(); err == nil {
+		t.Errorf("Parser.loadSources() should fail")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Parser.loadSources() took %v, failure should cancel other sources", elapsed)
+	}
+}
+
+// Source that ignores context cancellation
+type hangingSource struct{}
+
+func (s *hangingSource) Name() string {
+	return "hanging"
+}
+
+func (s *hangingSource) Load(ctx context.Context) (map[string]string, error) {
+	time.Sleep(time.Hour)
+	return nil, nil
+}
+
+func TestParser_loadSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      Source
+		timeouts    map[string]time.Duration
+		want        map[string]string
+		wantErr     bool
+		wantTimeout bool
+	}{
+		{name: "no timeout", source: &staticSource{name: "one", values: map[string]string{"a": "1"}}, want: map[string]string{"a": "1"}},
+		{name: "in time", source: &staticSource{name: "one", values: map[string]string{"a": "1"}, delay: time.Millisecond}, timeouts: map[string]time.Duration{"one": time.Second}, want: map[string]string{"a": "1"}},
+		{name: "other source timeout", source: &staticSource{name: "one", values: map[string]string{"a": "1"}, delay: 20 * time.Millisecond}, timeouts: map[string]time.Duration{"two": time.Millisecond}, want: map[string]string{"a": "1"}},
+		{name: "timeout", source: &staticSource{name: "one", delay: time.Hour}, timeouts: map[string]time.Duration{"one": 10 * time.Millisecond}, wantErr: true, wantTimeout: true},
+		{name: "ignored context", source: &hangingSource{}, timeouts: map[string]time.Duration{"hanging": 10 * time.Millisecond}, wantErr: true, wantTimeout: true},
+		{name: "error", source: &staticSource{name: "one", err: errors.New("unavailable")}, timeouts: map[string]time.Duration{"one": time.Second}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{}
+			for name, timeout := range tt.timeouts {
+				WithSourceTimeout(name, timeout)(p)
+			}
+			got, err := p.loadSource(context.Background(), tt.source)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parser.loadSource() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			var timeoutErr *SourceTimeoutError
+			if errors.As(err, &timeoutErr) != tt.wantTimeout {
+				t.Errorf("Parser.loadSource() error = %v, wantTimeout %v", err, tt.wantTimeout)
+			}
+			if tt.wantTimeout && timeoutErr.Source != tt.source.Name() {
+				t.Errorf("Parser.loadSource() timeout source = %v, want %v", timeoutErr.Source, tt.source.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parser.loadSource() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Source that fails specified number of first loads
+type flakySource struct {
+	failures int
+	loads    int
+}
+
+func (s *flakySource) Name() string {
+	return "flaky"
+}
+
+func (s *flakySource) Load(ctx context.Context) (map[string]string, error) {
+	s.loads++
+	if s.loads <= s.failures {
+		return nil, errors.New("unavailable")
+	}
+	return map[string]string{"a": "1"}, nil
+}
+
+func TestParser_loadSourceWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    *flakySource
+		retries   int
+		wantLoads int
+		wantErr   bool
+	}{
+		{name: "no retry", source: &flakySource{failures: 1}, retries: 0, wantLoads: 1, wantErr: true},
+		{name: "recovered", source: &flakySource{failures: 2}, retries: 3, wantLoads: 3},
+		{name: "exhausted", source: &flakySource{failures: 5}, retries: 2, wantLoads: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{}
+			WithSourceRetry("flaky", tt.retries, time.Millisecond)(p)
+			_, err := p.loadSourceWithRetry(context.Background(), tt.source)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parser.loadSourceWithRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.source.loads != tt.wantLoads {
+				t.Errorf("Parser.loadSourceWithRetry() loads = %v, want %v", tt.source.loads, tt.wantLoads)
+			}
+		})
+	}
+
+	// Canceled context stops retrying
+	p := &Parser{}
+	WithSourceRetry("flaky", 10, time.Hour)(p)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	source := &flakySource{failures: 10}
+	if _, err := p.loadSourceWithRetry(ctx, source); err == nil || source.loads != 1 {
+		t.Errorf("Parser.loadSourceWithRetry() error = %v, loads = %v", err, source.loads)
+	}
+}
+
+func Test_withJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if got := withJitter(100 * time.Millisecond); got < 50*time.Millisecond || got > 100*time.Millisecond {
+			t.Fatalf("withJitter() = %v, out of range", got)
+		}
+	}
+	if got := withJitter(0); got != 0 {
+		t.Errorf("withJitter() = %v, want 0", got)
+	}
+}
+
+func TestParser_loadSourcesLenient(t *testing.T) {
+	tests := []struct {
+		name         string
+		lenient      []string
+		sources      []Source
+		want         map[string]string
+		wantWarnings int
+		wantErr      bool
+	}{
+		{
+			name:    "strict",
+			sources: []Source{&staticSource{name: "one", err: errors.New("unavailable")}},
+			wantErr: true,
+		},
+		{
+			name:    "all lenient",
+			lenient: []string{},
+			sources: []Source{
+				&staticSource{name: "one", err: errors.New("unavailable")},
+				&staticSource{name: "two", values: map[string]string{"a": "two"}},
+			},
+			want:         map[string]string{"a": "two"},
+			wantWarnings: 1,
+		},
+		{
+			name:    "named lenient",
+			lenient: []string{"one"},
+			sources: []Source{
+				&staticSource{name: "one", err: errors.New("unavailable")},
+				&staticSource{name: "two", values: map[string]string{"a": "two"}},
+			},
+			want:         map[string]string{"a": "two"},
+			wantWarnings: 1,
+		},
+		{
+			name:    "critical source",
+			lenient: []string{"one"},
+			sources: []Source{
+				&staticSource{name: "one", values: map[string]string{"a": "one"}},
+				&staticSource{name: "two", err: errors.New("unavailable")},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{}
+			if tt.lenient != nil {
+				WithLenientSources(tt.lenient...)(p)
+			}
+			for _, src := range tt.sources {
+				WithSource(src)(p)
+			}
+			err := p.loadSources()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parser.loadSources() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(tt.want, p.parsedCfg) {
+				t.Errorf("Parser.loadSources() = %v, want %v", p.parsedCfg, tt.want)
+			}
+			if len(p.Warnings()) != tt.wantWarnings {
+				t.Errorf("Parser.Warnings() = %v, want %v warnings", p.Warnings(), tt.wantWarnings)
+			}
+		})
+	}
+}
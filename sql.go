@@ -0,0 +1,61 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Default query of SQLSource. Expected table with key and value columns
+const defaultSQLQuery = "SELECT key, value FROM config"
+
+// Source that loads values by key/value query of database.
+// Query should return exactly two columns: config name (nested names separated with ".") and its value. Rows with NULL value are skipped
+type SQLSource struct {
+	DB    *sql.DB
+	Query string        // Ex.: SELECT name, value FROM settings WHERE tenant_id = $1. Default is "SELECT key, value FROM config"
+	Args  []interface{} // Query arguments. Ex.: tenant id
+}
+
+func (s *SQLSource) Name() string {
+	return "sql"
+}
+
+// Run query and collect its rows
+func (s *SQLSource) Load(ctx context.Context) (map[string]string, error) {
+	if s.DB == nil {
+		return nil, errors.New("Database is not set")
+	}
+
+	query := s.Query
+	if query == "" {
+		query = defaultSQLQuery
+	}
+
+	rows, err := s.DB.QueryContext(ctx, query, s.Args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]string)
+	for rows.Next() {
+		var key string
+		var value sql.NullString
+		err = rows.Scan(&key, &value)
+		if err != nil {
+			return nil, err
+		}
+		if !value.Valid {
+			continue
+		}
+		result[key] = value.String
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
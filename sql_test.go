@@ -0,0 +1,98 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Fake sql driver. Query is ignored, first argument selects tenant which rows will be returned
+type fakeDriver struct {
+	tenants map[string][][]driver.Value
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+type fakeStmt struct{ d *fakeDriver }
+
+type fakeRows struct {
+	rows [][]driver.Value
+	pos  int
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{d: c.d}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	tenant := ""
+	if len(args) > 0 {
+		tenant, _ = args[0].(string)
+	}
+	rows, ok := s.d.tenants[tenant]
+	if !ok {
+		return nil, errors.New("unknown tenant")
+	}
+	return &fakeRows{rows: rows}, nil
+}
+
+func (r *fakeRows) Columns() []string { return []string{"key", "value"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+func TestSQLSource_Load(t *testing.T) {
+	driverName := fmt.Sprintf("config_fake_%d", time.Now().UnixNano()) // Drivers can't be unregistered, so repeated runs need new name
+	sql.Register(driverName, &fakeDriver{tenants: map[string][][]driver.Value{
+		"":    {{"port", "8080"}},
+		"one": {{"port", "9090"}, {"db.host", "localhost"}, {"db.pass", nil}},
+	}})
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		name    string
+		source  SQLSource
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "default query", source: SQLSource{DB: db}, want: map[string]string{"port": "8080"}},
+		{name: "tenant", source: SQLSource{DB: db, Query: "SELECT name, value FROM settings WHERE tenant = $1", Args: []interface{}{"one"}}, want: map[string]string{"port": "9090", "db.host": "localhost"}},
+		{name: "query error", source: SQLSource{DB: db, Args: []interface{}{"zzz"}}, wantErr: true},
+		{name: "no db", source: SQLSource{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.source.Load(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SQLSource.Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SQLSource.Load() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
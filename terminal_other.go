@@ -0,0 +1,12 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris)
+
+package config
+
+import (
+	"os"
+)
+
+// Return width of terminal. Terminal size can't be detected on this platform, so 0 is returned
+func terminalWidth(file *os.File) int {
+	return 0
+}
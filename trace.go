@@ -0,0 +1,135 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+)
+
+// Resolution of single config field by one filling of config struct
+type FieldTrace struct {
+	Name       string
+	Type       string           // Go type of field. Ex.: "time.Duration"
+	Candidates []TraceCandidate // Consulted sources in order of priority, the last found one wins
+	Source     string           // Source of written value. Empty if value is not set
+	Key        string           // Key of written value in its source
+	Value      string           // Raw written value
+	Result     string           // Converted value, formatted with %v
+	Error      string           // Conversion error
+}
+
+// Lookup of field value in single source. Secret values are redacted
+type TraceCandidate struct {
+	Source string // cli, cfg, env, default or name of external source
+	Key    string // Flag, env variable or config file key. First looked key if value is not found
+	Value  string
+	Found  bool
+}
+
+// Set handler of resolution trace: for each field - consulted sources, raw values found in them, the winning one
+// and result of conversion. Handler is called after each filling of config struct (by Parse, Reload or polling),
+// even if it is failed, so broken value can be found. Handler is called under lock, so it shouldn't call parser.
+// Tracing makes parsing slower, so it is meant for debugging
+func WithTrace(handler func(trace []FieldTrace)) Option {
+	return func(p *Parser) {
+		p.onTrace = handler
+	}
+}
+
+// Start trace of field with lookups of all its sources. Return nil if tracing is off
+func (p *Parser) traceField(field *structField) *FieldTrace {
+	if p.onTrace == nil {
+		return nil
+	}
+
+	tags := field.tags
+	trace := &FieldTrace{Name: tags.name, Candidates: []TraceCandidate{}}
+	if t := p.fieldType(field); t != nil {
+		trace.Type = t.String()
+	}
+	add := func(source string, keys []string, value, key string, found bool) {
+		if !found {
+			key = keys[0]
+			value = ""
+		}
+		if found {
+			value = p.shownValue(field, value)
+		}
+		trace.Candidates = append(trace.Candidates, TraceCandidate{Source: source, Key: key, Value: value, Found: found})
+	}
+
+	if tags.mode == 0 || tags.mode&modeEnv > 0 {
+		keys := p.envKeys(tags)
+		value, key, found := lookupNames(keys, p.lookupEnvKey)
+		add(sourceEnv, keys, value, key, found)
+		if items, ok := p.lookupEnvItems(field); ok {
+			add(sourceEnv, keys, strings.ReplaceAll(items, separatorEnvItems, tags.listSeparator()), keys[0]+"_*", true)
+		}
+	}
+	if tags.mode == 0 || tags.mode&modeCfg > 0 {
+		names := append([]string{tags.cfgName()}, tags.aliases...)
+		value, name, found := lookupNames(names, p.lookupCfg)
+		source := sourceCfg
+		if found {
+			name = p.keyOf(p.parsedCfg, name)
+			if origin, ok := p.cfgOrigins[name]; ok {
+				source = origin
+			}
+		}
+		add(source, names, value, name, found)
+		if items, itemsSource, ok := p.lookupCfgItems(field); ok {
+			add(itemsSource, names, strings.ReplaceAll(items, separatorEnvItems, tags.listSeparator()), tags.cfgName()+indexOpen+"*"+indexClose, true)
+		}
+	}
+	if tags.mode == 0 || tags.mode&modeCli > 0 {
+		names := append([]string{tags.cliName()}, tags.aliases...)
+		for i := range names {
+			names[i] = "--" + names[i]
+		}
+		value, name, found := lookupNames(names, func(name string) (string, bool) {
+			return p.lookupCli(strings.TrimPrefix(name, "--"))
+		})
+		add(sourceCli, names, value, name, found)
+	}
+	add(sourceDefault, []string{""}, tags.defaultValue, "", tags.hasDefaultValue)
+
+	p.trace = append(p.trace, trace)
+	return trace
+}
+
+// Save written value (as it is shown, see shownValue) and result of its conversion into trace of field.
+// Result is rendered like Redact does, so secrets of nested structs are not shown
+func (t *FieldTrace) written(field reflect.Value, tags structFieldTags, value, source, key string, err error) {
+	if t == nil {
+		return
+	}
+
+	t.Source, t.Key, t.Value = source, key, value
+	switch {
+	case err != nil && tags.secret:
+		t.Value = redacted
+		t.Error = "Wrong value" // Conversion errors can contain value itself
+	case err != nil:
+		t.Error = err.Error()
+	case tags.secret:
+		t.Value, t.Result = redacted, redacted
+	default:
+		buffer := bytes.NewBufferString("")
+		writeRedactedValue(buffer, field)
+		t.Result = buffer.String()
+	}
+}
+
+// Pass trace of last filling to handler
+func (p *Parser) emitTrace() {
+	if p.onTrace == nil {
+		return
+	}
+
+	result := make([]FieldTrace, len(p.trace))
+	for i, trace := range p.trace {
+		result[i] = *trace
+	}
+	p.trace = nil
+	p.onTrace(result)
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWithTrace(t *testing.T) {
+	type testStruct struct {
+		Port     int           `config:"name:port;mode:env,cli"`
+		Timeout  time.Duration `config:"name:timeout;mode:cfg;default:30s"`
+		Password string        `config:"name:password;mode:env;secret"`
+		Level    string        `config:"name:level;mode:cli"`
+	}
+
+	os.Args = []string{"/app/test", "--port=9090"}
+	t.Setenv("PORT", "8080")
+	t.Setenv("PASSWORD", "qwerty")
+
+	var traces [][]FieldTrace
+	var cfg testStruct
+	p, err := NewParser(&cfg, WithTrace(func(trace []FieldTrace) {
+		traces = append(traces, trace)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	want := []FieldTrace{
+		{
+			Name: "port",
+			Type: "int",
+			Candidates: []TraceCandidate{
+				{Source: "env", Key: "PORT", Value: "8080", Found: true},
+				{Source: "cli", Key: "--port", Value: "9090", Found: true},
+				{Source: "default"},
+			},
+			Source: "cli",
+			Key:    "--port",
+			Value:  "9090",
+			Result: "9090",
+		},
+		{
+			Name: "timeout",
+			Type: "time.Duration",
+			Candidates: []TraceCandidate{
+				{Source: "cfg", Key: "timeout"},
+				{Source: "default", Value: "30s", Found: true},
+			},
+			Source: "default",
+			Value:  "30s",
+			Result: "30s",
+		},
+		{
+			Name: "password",
+			Type: "string",
+			Candidates: []TraceCandidate{
+				{Source: "env", Key: "PASSWORD", Value: redacted, Found: true},
+				{Source: "default"},
+			},
+			Source: "env",
+			Key:    "PASSWORD",
+			Value:  redacted,
+			Result: redacted,
+		},
+		{
+			Name: "level",
+			Type: "string",
+			Candidates: []TraceCandidate{
+				{Source: "cli", Key: "--level"},
+				{Source: "default"},
+			},
+		},
+	}
+	if len(traces) != 1 || !reflect.DeepEqual(traces[0], want) {
+		t.Errorf("WithTrace() got %+v, want %+v", traces, want)
+	}
+
+	os.Args = []string{"/app/test", "--port=abc"}
+	if _, err = p.Reload(); err == nil {
+		t.Fatalf("Parser.Reload() should fail with wrong port")
+	}
+	if len(traces) != 2 || traces[1][0].Error == "" || traces[1][0].Value != "abc" {
+		t.Errorf("WithTrace() of failed reload = %+v, want conversion error of port", traces[len(traces)-1])
+	}
+}
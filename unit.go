@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
+
+// Available values of unit tag
+const (
+	unitBytes = "bytes"
+	unitSI    = "si"
+)
+
+// Keys - available units, values - parsers of value with unit suffix into number
+var units = map[string]func(value string) (float64, error){
+	unitBytes: parseByteSize,
+	unitSI:    parseSI,
+}
+
+// Multipliers of byte size suffixes. Suffixes are compared lowercased
+var byteSizes = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1e3,
+	"kb":  1e3,
+	"m":   1e6,
+	"mb":  1e6,
+	"g":   1e9,
+	"gb":  1e9,
+	"t":   1e12,
+	"tb":  1e12,
+	"p":   1e15,
+	"pb":  1e15,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// Multipliers of SI prefixes. Prefixes are case-sensitive, so "m" is milli and "M" is mega
+var siPrefixes = map[string]float64{
+	"":  1,
+	"n": 1e-9,
+	"u": 1e-6,
+	"µ": 1e-6,
+	"m": 1e-3,
+	"k": 1e3,
+	"K": 1e3,
+	"M": 1e6,
+	"G": 1e9,
+	"T": 1e12,
+	"P": 1e15,
+	"E": 1e18,
+}
+
+// Check unit name of tag
+func parseUnitTag(value string) (string, error) {
+	if _, ok := units[value]; !ok {
+		return "", errors.New(fmt.Sprintf("Unknown unit %s. Available units: %s", value, strings.Join(maps.Keys(units), ", ")))
+	}
+
+	return value, nil
+}
+
+// Parse value with unit suffix and put it into numeric field
+func writeUnitToField(field reflect.Value, value string, tags structFieldTags) error {
+	number, err := units[tags.unit](value)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Wrong value %s of %s: %s", value, tags.name, err))
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if number != math.Trunc(number) || number < math.MinInt64 || number >= math.MaxInt64 || field.OverflowInt(int64(number)) {
+			return errors.New(fmt.Sprintf("Value %s of %s is out of range of %s", value, tags.name, field.Type()))
+		}
+		field.SetInt(int64(number))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if number != math.Trunc(number) || number < 0 || number >= math.MaxUint64 || field.OverflowUint(uint64(number)) {
+			return errors.New(fmt.Sprintf("Value %s of %s is out of range of %s", value, tags.name, field.Type()))
+		}
+		field.SetUint(uint64(number))
+	case reflect.Float32, reflect.Float64:
+		if field.OverflowFloat(number) {
+			return errors.New(fmt.Sprintf("Value %s of %s is out of range of %s", value, tags.name, field.Type()))
+		}
+		field.SetFloat(number)
+	default:
+		return errors.New(fmt.Sprintf("Unit of %s is supported just for numeric fields", tags.name))
+	}
+
+	return nil
+}
+
+// Split value into number and suffix. Ex.: "2.5 MB" gives 2.5 and "MB"
+func splitNumber(value string) (float64, string, error) {
+	value = strings.TrimSpace(value)
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return !(r >= '0' && r <= '9') && r != '.' && r != '-' && r != '+'
+	})
+	if i == -1 {
+		i = len(value)
+	}
+
+	number, err := strconv.ParseFloat(value[:i], 64)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return number, strings.TrimSpace(value[i:]), nil
+}
+
+// Parse human-readable byte size. Ex.: "10MB" (decimal, 10^6) or "512KiB" (binary, 2^10)
+func parseByteSize(value string) (float64, error) {
+	number, suffix, err := splitNumber(value)
+	if err != nil {
+		return 0, err
+	}
+
+	multiplier, ok := byteSizes[strings.ToLower(suffix)]
+	if !ok {
+		return 0, errors.New(fmt.Sprintf("Unknown byte size suffix %s", suffix))
+	}
+
+	return number * multiplier, nil
+}
+
+// Parse number with SI prefix. Ex.: "1k" gives 1000, "2.5M" gives 2500000
+func parseSI(value string) (float64, error) {
+	number, suffix, err := splitNumber(value)
+	if err != nil {
+		return 0, err
+	}
+
+	multiplier, ok := siPrefixes[suffix]
+	if !ok {
+		return 0, errors.New(fmt.Sprintf("Unknown SI prefix %s", suffix))
+	}
+
+	return number * multiplier, nil
+}
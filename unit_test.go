@@ -0,0 +1,128 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseByteSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{value: "1024", want: 1024},
+		{value: "10MB", want: 10e6},
+		{value: "512KiB", want: 512 * 1024},
+		{value: "1.5 gib", want: 1.5 * (1 << 30)},
+		{value: "2k", want: 2000},
+		{value: "10XB", wantErr: true},
+		{value: "MB", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseByteSize(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseByteSize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseByteSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseSI(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{value: "100", want: 100},
+		{value: "1k", want: 1000},
+		{value: "2.5M", want: 2.5e6},
+		{value: "500m", want: 0.5},
+		{value: "1G", want: 1e9},
+		{value: "1Z", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSI(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSI() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseSI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_writeUnitToField(t *testing.T) {
+	type args struct {
+		Int64   int64
+		Uint8   uint8
+		Float64 float64
+		String  string
+	}
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		unit    string
+		want    args
+		wantErr bool
+	}{
+		{name: "int64", key: "Int64", value: "64MiB", want: args{Int64: 64 << 20}},
+		{name: "si int", key: "Int64", value: "2.5k", unit: unitSI, want: args{Int64: 2500}},
+		{name: "si float", key: "Float64", value: "1.5M", unit: unitSI, want: args{Float64: 1.5e6}},
+		{name: "si fraction", key: "Int64", value: "1m", unit: unitSI, wantErr: true},
+		{name: "overflow", key: "Uint8", value: "1KB", wantErr: true},
+		{name: "fraction", key: "Int64", value: "1.5B", wantErr: true},
+		{name: "not numeric", key: "String", value: "1KB", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unit := tt.unit
+			if unit == "" {
+				unit = unitBytes
+			}
+			var got args
+			err := writeUnitToField(reflect.ValueOf(&got).Elem().FieldByName(tt.key), tt.value, structFieldTags{name: "key", unit: unit})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("writeUnitToField() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("writeUnitToField() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_unit(t *testing.T) {
+	type testStruct struct {
+		CacheSize int64 `config:"name:cache_size;mode:cli;unit:bytes;default:512KiB"`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.fillStructWithValues(&cfg, ""); err != nil {
+		t.Fatalf("Parser.fillStructWithValues() error = %v", err)
+	}
+	if cfg.CacheSize != 512*1024 {
+		t.Errorf("Parser.fillStructWithValues() = %v, want %v", cfg.CacheSize, 512*1024)
+	}
+
+	var wrong struct {
+		Size int `config:"name:size;unit:zzz"`
+	}
+	if _, err = NewParser(&wrong); err == nil {
+		t.Errorf("NewParser() should fail with unknown unit")
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Return keys of config file and env variables with env prefix, that are not bound to any field, so typos like
+// "databse.host" can be reported after Parse instead of being silently ignored. Keys of external sources are not
+// checked, because they often keep configs of other apps. Env variables are checked just if env prefix is set
+// (by Parse or WithEnvPrefixes). Result is sorted by source and key
+func (p *Parser) UnusedKeys() []SourceInfo {
+	if p.mu != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+	}
+
+	result := []SourceInfo{}
+	for key := range p.parsedCfg {
+		if _, ok := p.cfgOrigins[key]; ok {
+			continue
+		}
+		if base, _, _, ok := splitIndexedKey(key); ok { // Items of arrays. Ex.: servers[0].host
+			key = base
+		}
+		if p.fieldByCfgName(key) == nil {
+			result = append(result, SourceInfo{Source: sourceCfg, Key: key})
+		}
+	}
+
+	for _, env := range p.environ() {
+		key, _, _ := strings.Cut(env, "=")
+		if p.hasEnvPrefix(key) && !p.isBoundEnvKey(key) {
+			result = append(result, SourceInfo{Source: sourceEnv, Key: key})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Source != result[j].Source {
+			return result[i].Source < result[j].Source
+		}
+		return result[i].Key < result[j].Key
+	})
+
+	return result
+}
+
+// Check if env variable starts with some not empty env prefix
+func (p *Parser) hasEnvPrefix(key string) bool {
+	for _, prefix := range p.allEnvPrefixes() {
+		if prefix != "" && strings.HasPrefix(strings.ToUpper(key), strings.ToUpper(prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check if env variable sets some field: by its name, with _FILE suffix or as item of slice or map field
+func (p *Parser) isBoundEnvKey(key string) bool {
+	if key == p.envJSON {
+		return true
+	}
+
+	for _, field := range p.fields {
+		if field.tags.mode != 0 && field.tags.mode&modeEnv == 0 {
+			continue
+		}
+		t := p.fieldType(field)
+		hasItems := t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Map)
+		for _, envKey := range p.envKeys(field.tags) {
+			if key == envKey || key == envKey+envFileSuffix || hasItems && strings.HasPrefix(key, envKey+"_") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParser_UnusedKeys(t *testing.T) {
+	type server struct {
+		Host string `config:"name:host"`
+	}
+	type testStruct struct {
+		Port    int      `config:"name:port"`
+		Tags    []string `config:"name:tags"`
+		Servers []server `config:"name:servers"`
+		DB      struct {
+			Host string `config:"name:host"`
+		} `config:"name:db"`
+		Config string `config:"name:config;mode:cli"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{"port": 80, "databse": {"host": "db"}, "db": {"host": "db", "prot": 1}, "servers": [{"host": "a"}], "tags": ["a"]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"/app/test", "--config=" + path}
+	t.Setenv("APP_PORT", "8080")
+	t.Setenv("APP_PROT", "8080")
+	t.Setenv("APP_TAGS_0", "b")
+	t.Setenv("APP_DB_HOST_FILE", path)
+	t.Setenv("OTHER_PORT", "1")
+
+	var cfg testStruct
+	src := &staticSource{name: "consul", values: map[string]string{"unknown": "1"}}
+	p, err := NewParser(&cfg, WithEnvPrefixes("APP_"), WithSource(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Parse("config", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	want := []SourceInfo{
+		{Source: "cfg", Key: "databse.host"},
+		{Source: "cfg", Key: "db.prot"},
+		{Source: "env", Key: "APP_PROT"},
+	}
+	if got := p.UnusedKeys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parser.UnusedKeys() = %v, want %v", got, want)
+	}
+}
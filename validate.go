@@ -0,0 +1,329 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+// Register named check of field values, that can be used in tags. Ex.: `validate:port`.
+// Validator receives converted field value. Should be called before Parse
+func (p *Parser) RegisterValidator(name string, validator func(value interface{}) error) {
+	if p.validators == nil {
+		p.validators = make(map[string]func(value interface{}) error)
+	}
+	p.validators[name] = validator
+}
+
+// Check parsed values against validation tags of fields. Should be called after filling of config struct
+func (p *Parser) validate() error {
+	return p.checkRequired()
+}
+
+// Config struct (or its nested struct) with own checks, called after filling. Ex.: to check related fields together
+type Validator interface {
+	Validate() error
+}
+
+// Call Validate of nested structs (including items of slices), and then of struct itself
+func callValidate(v reflect.Value, path string) error {
+	if v.Kind() == reflect.Struct {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			fieldPath := v.Type().Field(i).Name
+			if path != "" {
+				fieldPath = fmt.Sprintf("%s%s%s", path, separatorNested, fieldPath)
+			}
+
+			switch {
+			case isNestedStruct(field.Type()):
+				err := callValidate(field, fieldPath)
+				if err != nil {
+					return err
+				}
+			case isNestedStructPointer(field.Type()) && !field.IsNil():
+				err := callValidate(field.Elem(), fieldPath)
+				if err != nil {
+					return err
+				}
+			case field.Kind() == reflect.Slice && isNestedStruct(field.Type().Elem()):
+				for j := 0; j < field.Len(); j++ {
+					err := callValidate(field.Index(j), fmt.Sprintf("%s[%d]", fieldPath, j))
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	if validator, ok := v.Addr().Interface().(Validator); ok {
+		err := validator.Validate()
+		if err != nil && path != "" {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Check that all required fields (including ones with met required_if conditions) got value from some source or default.
+// Error lists all missing fields
+func (p *Parser) checkRequired() error {
+	missing := []string{}
+	for _, field := range p.fields {
+		if _, ok := p.values[field.tags.name]; ok || p.inNilStruct(field) {
+			continue
+		}
+
+		if field.tags.required {
+			missing = append(missing, fmt.Sprintf("%s (%s)", field.tags.name, p.expectedSources(field)))
+			continue
+		}
+
+		if len(field.tags.requiredIf) > 0 {
+			met, err := p.conditionsMet(field.tags.requiredIf, field.tags.name)
+			if err != nil {
+				return err
+			}
+			if met {
+				missing = append(missing, fmt.Sprintf("%s (%s) because of %s", field.tags.name, p.expectedSources(field), formatConditions(field.tags.requiredIf)))
+			}
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+
+	return errors.New(fmt.Sprintf("Missing required configs: %s", strings.Join(missing, ", ")))
+}
+
+// Parse list of conditions. Ex.: "tls_enabled=true,mode=server"
+func parseConditionsTag(name, value string) (map[string]string, error) {
+	conditions := make(map[string]string)
+	for _, item := range strings.Split(value, separatorList) {
+		pair := strings.SplitN(item, separatorPair, 2)
+		if len(pair) != 2 || pair[0] == "" {
+			return nil, errors.New(fmt.Sprintf("Wrong condition %s of tag %s. Should be name%svalue", item, name, separatorPair))
+		}
+		conditions[pair[0]] = pair[1]
+	}
+
+	return conditions, nil
+}
+
+// Check if all conditions are met by parsed values. Boolean values are compared by meaning, so "true" matches "yes"
+func (p *Parser) conditionsMet(conditions map[string]string, owner string) (bool, error) {
+	for name, expected := range conditions {
+		if p.fieldByConfigName(name) == nil {
+			return false, errors.New(fmt.Sprintf("Unknown config %s in conditions of %s", name, owner))
+		}
+
+		value, ok := p.values[name]
+		if !ok || !sameValue(value, expected) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Compare raw values. Boolean words are compared by meaning
+func sameValue(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	aBool, aErr := parseBoolTag("", a)
+	bBool, bErr := parseBoolTag("", b)
+	return a != "" && b != "" && aErr == nil && bErr == nil && aBool == bBool
+}
+
+// Format conditions sorted by name. Ex.: "mode=server, tls_enabled=true"
+func formatConditions(conditions map[string]string) string {
+	names := maps.Keys(conditions)
+	sort.Strings(names)
+	result := make([]string, len(names))
+	for i, name := range names {
+		result[i] = fmt.Sprintf("%s%s%s", name, separatorPair, conditions[name])
+	}
+
+	return strings.Join(result, ", ")
+}
+
+// Describe places where field value is looked for. Ex.: "cli --db_host, env APP_DB_HOST"
+func (p *Parser) expectedSources(field *structField) string {
+	result := []string{}
+	for _, mode := range modesOrder {
+		if field.tags.mode != 0 && field.tags.mode&modes[mode] == 0 {
+			continue
+		}
+		switch modes[mode] {
+		case modeCli:
+			result = append(result, fmt.Sprintf("%s --%s", mode, field.tags.cliName()))
+		case modeCfg:
+			result = append(result, fmt.Sprintf("%s %s", mode, field.tags.cfgName()))
+		case modeEnv:
+			result = append(result, fmt.Sprintf("%s %s", mode, p.envKeys(field.tags)[0]))
+		}
+	}
+
+	return strings.Join(result, ", ")
+}
+
+// Parse value of min or max tag. Limit of numeric field is its value (duration or number with unit for such fields),
+// limit of string, slice or map field is its length
+func parseLimitTag(t reflect.Type, unit, name, value string) (float64, error) {
+	var limit float64
+	var err error
+	switch {
+	case t == durationType:
+		var d time.Duration
+		d, err = time.ParseDuration(value)
+		limit = float64(d)
+	case isNumeric(t.Kind()) && unit != "":
+		limit, err = units[unit](value)
+	case isNumeric(t.Kind()):
+		limit, err = strconv.ParseFloat(value, 64)
+	case t.Kind() == reflect.String || t.Kind() == reflect.Slice || t.Kind() == reflect.Map || t.Kind() == reflect.Array:
+		var length uint64
+		length, err = strconv.ParseUint(value, 10, 64)
+		limit = float64(length)
+	default:
+		return 0, errors.New(fmt.Sprintf("Tag %s is supported just for numeric, string, slice and map fields", name))
+	}
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("Wrong value %s of tag %s: %s", value, name, err))
+	}
+
+	return limit, nil
+}
+
+// Check if kind is signed or unsigned integer
+func isInteger(kind reflect.Kind) bool {
+	return isNumeric(kind) && kind != reflect.Float32 && kind != reflect.Float64
+}
+
+// Check if kind is integer or float number
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// Check field value against min and max tags. Error contains raw value and its source (redacted for secrets)
+func (p *Parser) checkLimits(field reflect.Value, tags structFieldTags, value, source string) error {
+	if !tags.hasMin && !tags.hasMax {
+		return nil
+	}
+
+	var actual float64
+	what := "Value"
+	switch {
+	case field.CanInt():
+		actual = float64(field.Int())
+	case field.CanUint():
+		actual = float64(field.Uint())
+	case field.CanFloat():
+		actual = field.Float()
+	default:
+		actual = float64(field.Len())
+		what = "Length of value"
+	}
+
+	if tags.secret {
+		value = redacted
+	}
+	if tags.hasMin && actual < tags.min {
+		return errors.New(fmt.Sprintf("%s %s of %s (from %s) should be at least %s", what, value, tags.name, source, formatLimit(field.Type(), tags.min)))
+	}
+	if tags.hasMax && actual > tags.max {
+		return errors.New(fmt.Sprintf("%s %s of %s (from %s) should be at most %s", what, value, tags.name, source, formatLimit(field.Type(), tags.max)))
+	}
+
+	return nil
+}
+
+// Format limit for error message. Durations are shown as durations
+func formatLimit(t reflect.Type, limit float64) string {
+	if t == durationType {
+		return time.Duration(limit).String()
+	}
+
+	return strconv.FormatFloat(limit, 'f', -1, 64)
+}
+
+// Check that value (or each item of slice value) is one of allowed choices
+func (p *Parser) checkOneOf(field reflect.Value, tags structFieldTags, value, source string) error {
+	if len(tags.oneOf) == 0 {
+		return nil
+	}
+
+	items := []string{value}
+	if field.Kind() == reflect.Slice {
+		items = tags.splitList(value)
+	}
+
+Items:
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		for _, choice := range tags.oneOf {
+			if item == choice {
+				continue Items
+			}
+		}
+		if tags.secret {
+			item = redacted
+		}
+
+		return errors.New(fmt.Sprintf("Value %s of %s (from %s) should be one of: %s", item, tags.name, source, strings.Join(tags.oneOf, ", ")))
+	}
+
+	return nil
+}
+
+// Run validators listed in validate tag against field value
+func (p *Parser) runValidators(field reflect.Value, tags structFieldTags, value, source string) error {
+	for _, name := range tags.validators {
+		validator, ok := p.validators[name]
+		if !ok {
+			builtinName, arg, _ := strings.Cut(name, separatorPair)
+			builtin, isBuiltin := builtinValidators[builtinName]
+			if !isBuiltin {
+				return errors.New(fmt.Sprintf("Unknown validator %s of %s", name, tags.name))
+			}
+			validator = func(value interface{}) error {
+				return checkItems(reflect.ValueOf(value), func(item interface{}) error {
+					return builtin(item, arg)
+				})
+			}
+		}
+
+		err := validator(field.Interface())
+		if err != nil {
+			if tags.secret {
+				value = redacted
+			}
+			return fmt.Errorf("Wrong value %s of %s (from %s): %w", value, tags.name, source, err)
+		}
+	}
+
+	return nil
+}
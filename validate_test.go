@@ -0,0 +1,335 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParser_Parse_required(t *testing.T) {
+	type testStruct struct {
+		Host   string `config:"name:host;mode:cli,env;required"`
+		Pass   string `config:"name:pass;mode:env;required:true"`
+		Port   int    `config:"name:port;required;default:80"`
+		Level  string `config:"name:level;required:false"`
+		Prefix string `config:"name:prefix;mode:cli;default:app_"`
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "all set", args: []string{"/app/test", "--host=db"}, env: map[string]string{"APP_PASS": "qwerty"}},
+		{name: "empty value", args: []string{"/app/test", "--host="}, env: map[string]string{"APP_PASS": ""}},
+		{name: "missing", args: []string{"/app/test"}, wantErr: "Missing required configs: host (cli --host, env APP_HOST), pass (env APP_PASS)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = tt.args
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			var cfg testStruct
+			p, err := NewParser(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.Parse("", "prefix")
+			if (err != nil) != (tt.wantErr != "") {
+				t.Fatalf("Parser.Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parser.Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParser_checkLimits(t *testing.T) {
+	type testStruct struct {
+		Port    int           `config:"name:port;min:1;max:65535"`
+		Ratio   float64       `config:"name:ratio;min:0;max:1"`
+		Timeout time.Duration `config:"name:timeout;min:1s;max:1m"`
+		Size    int64         `config:"name:size;unit:bytes;max:1MiB"`
+		Name    string        `config:"name:name;min:3;max:5"`
+		Hosts   []string      `config:"name:hosts;min:1"`
+		Pass    string        `config:"name:pass;min:8;secret"`
+	}
+
+	tests := []struct {
+		name    string
+		cli     map[string]string
+		wantErr string
+	}{
+		{name: "valid", cli: map[string]string{"port": "8080", "ratio": "0.5", "timeout": "30s", "size": "1KiB", "name": "app", "hosts": "a", "pass": "qwertyui"}},
+		{name: "min", cli: map[string]string{"port": "0"}, wantErr: "Value 0 of port (from cli) should be at least 1"},
+		{name: "max", cli: map[string]string{"ratio": "1.5"}, wantErr: "Value 1.5 of ratio (from cli) should be at most 1"},
+		{name: "duration", cli: map[string]string{"timeout": "2m"}, wantErr: "Value 2m of timeout (from cli) should be at most 1m0s"},
+		{name: "unit", cli: map[string]string{"size": "2MB"}, wantErr: "Value 2MB of size (from cli) should be at most 1048576"},
+		{name: "string length", cli: map[string]string{"name": "ab"}, wantErr: "Length of value ab of name (from cli) should be at least 3"},
+		{name: "slice length", cli: map[string]string{"hosts": ""}, wantErr: "Length of value  of hosts (from cli) should be at least 1"},
+		{name: "secret", cli: map[string]string{"pass": "qwerty"}, wantErr: "Length of value **** of pass (from cli) should be at least 8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg testStruct
+			p, err := NewParser(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.parsedCli = tt.cli
+			err = p.fillStructWithValues(&cfg, "")
+			if (err != nil) != (tt.wantErr != "") {
+				t.Fatalf("Parser.fillStructWithValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && err.Error() != tt.wantErr {
+				t.Errorf("Parser.fillStructWithValues() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_parseLimitTag(t *testing.T) {
+	var wrong struct {
+		Port    int    `config:"name:port;min:ZZZ"`
+		Name    string `config:"name:name;max:-1"`
+		Enabled bool   `config:"name:enabled;min:1"`
+	}
+	typeOfT := reflect.TypeOf(wrong)
+	for i := 0; i < typeOfT.NumField(); i++ {
+		p := &Parser{fields: make(map[string]*structField)}
+		if err := p.newStructField(typeOfT.Field(i), nil); err == nil {
+			t.Errorf("Parser.newStructField() should fail for %s", typeOfT.Field(i).Name)
+		}
+	}
+}
+
+func TestParser_checkOneOf(t *testing.T) {
+	type testStruct struct {
+		Level  string   `config:"name:level;oneof:debug,info,warn,error;desc:Log level"`
+		Levels []string `config:"name:levels;oneof:debug,info"`
+	}
+
+	tests := []struct {
+		name    string
+		cli     map[string]string
+		wantErr string
+	}{
+		{name: "valid", cli: map[string]string{"level": "info", "levels": "debug, info"}},
+		{name: "empty slice", cli: map[string]string{"levels": ""}},
+		{name: "wrong", cli: map[string]string{"level": "trace"}, wantErr: "Value trace of level (from cli) should be one of: debug, info, warn, error"},
+		{name: "wrong item", cli: map[string]string{"levels": "debug,warn"}, wantErr: "Value warn of levels (from cli) should be one of: debug, info"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg testStruct
+			p, err := NewParser(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.parsedCli = tt.cli
+			err = p.fillStructWithValues(&cfg, "")
+			if (err != nil) != (tt.wantErr != "") {
+				t.Fatalf("Parser.fillStructWithValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && err.Error() != tt.wantErr {
+				t.Errorf("Parser.fillStructWithValues() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	var cfg testStruct
+	p, _ := NewParser(&cfg)
+	if got, want := p.Help(""), "--level Log level [debug|info|warn|error]\n"; got != want {
+		t.Errorf("Parser.Help() = %v, want %v", got, want)
+	}
+}
+
+func TestParser_RegisterValidator(t *testing.T) {
+	type testStruct struct {
+		Port  int    `config:"name:port;validate:even,positive"`
+		Name  string `config:"name:name;validate:lower"`
+		Other string `config:"name:other;validate:zzz"`
+	}
+
+	errOdd := errors.New("should be even")
+	tests := []struct {
+		name    string
+		cli     map[string]string
+		wantErr error
+	}{
+		{name: "valid", cli: map[string]string{"port": "8080", "name": "app"}},
+		{name: "first validator", cli: map[string]string{"port": "8081"}, wantErr: errOdd},
+		{name: "second validator", cli: map[string]string{"port": "-2"}},
+		{name: "string", cli: map[string]string{"name": "App"}},
+		{name: "unknown", cli: map[string]string{"other": "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg testStruct
+			p, err := NewParser(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.RegisterValidator("even", func(value interface{}) error {
+				if value.(int)%2 != 0 {
+					return errOdd
+				}
+				return nil
+			})
+			p.RegisterValidator("positive", func(value interface{}) error {
+				if value.(int) <= 0 {
+					return errors.New("should be positive")
+				}
+				return nil
+			})
+			p.RegisterValidator("lower", func(value interface{}) error {
+				if strings.ToLower(value.(string)) != value.(string) {
+					return errors.New("should be lowercase")
+				}
+				return nil
+			})
+
+			p.parsedCli = tt.cli
+			err = p.fillStructWithValues(&cfg, "")
+			wantErr := tt.name != "valid"
+			if (err != nil) != wantErr {
+				t.Fatalf("Parser.fillStructWithValues() error = %v, wantErr %v", err, wantErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parser.fillStructWithValues() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type testTLS struct {
+	Cert string `config:"name:cert"`
+	Key  string `config:"name:key"`
+}
+
+func (t testTLS) Validate() error {
+	if (t.Cert == "") != (t.Key == "") {
+		return errors.New("cert and key should be set together")
+	}
+	return nil
+}
+
+type testServer struct {
+	Host string `config:"name:host"`
+}
+
+func (s *testServer) Validate() error {
+	if s.Host == "localhost" {
+		return errors.New("host should not be localhost")
+	}
+	return nil
+}
+
+type testValidated struct {
+	Port    int          `config:"name:port;mode:cli"`
+	TLS     testTLS      `config:"name:tls;mode:cli"`
+	Servers []testServer `config:"name:servers;mode:cli"`
+	Prefix  string       `config:"name:prefix;mode:cli"`
+}
+
+func (c *testValidated) Validate() error {
+	if c.Port == 0 && c.TLS.Cert != "" {
+		return errors.New("port should be set for tls")
+	}
+	return nil
+}
+
+func TestParser_Parse_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "valid", args: []string{"/app/test", "--port=443", "--tls.cert=a", "--tls.key=b", `--servers=[{"host":"a"}]`}},
+		{name: "nested", args: []string{"/app/test", "--port=443", "--tls.cert=a"}, wantErr: "TLS: cert and key should be set together"},
+		{name: "slice item", args: []string{"/app/test", `--servers=[{"host":"a"},{"host":"localhost"}]`}, wantErr: "Servers[1]: host should not be localhost"},
+		{name: "root", args: []string{"/app/test", "--tls.cert=a", "--tls.key=b"}, wantErr: "port should be set for tls"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = tt.args
+
+			var cfg testValidated
+			p, err := NewParser(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.Parse("", "prefix")
+			if (err != nil) != (tt.wantErr != "") {
+				t.Fatalf("Parser.Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && err.Error() != tt.wantErr {
+				t.Errorf("Parser.Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_requiredIf(t *testing.T) {
+	type testStruct struct {
+		TLSEnabled bool   `config:"name:tls_enabled;mode:cli"`
+		Mode       string `config:"name:mode;mode:cli"`
+		Cert       string `config:"name:cert;mode:cli;required_if:tls_enabled=true"`
+		Peer       string `config:"name:peer;mode:cli;required_if:tls_enabled=true,mode=cluster"`
+		Prefix     string `config:"name:prefix;mode:cli"`
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "disabled", args: []string{"/app/test"}},
+		{name: "set", args: []string{"/app/test", "--tls_enabled=yes", "--cert=a"}},
+		{name: "missing", args: []string{"/app/test", "--tls_enabled=t"}, wantErr: "Missing required configs: cert (cli --cert) because of tls_enabled=true"},
+		{name: "all conditions", args: []string{"/app/test", "--tls_enabled=true", "--cert=a", "--mode=cluster"}, wantErr: "Missing required configs: peer (cli --peer) because of mode=cluster, tls_enabled=true"},
+		{name: "not all conditions", args: []string{"/app/test", "--tls_enabled=true", "--cert=a", "--mode=single"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = tt.args
+
+			var cfg testStruct
+			p, err := NewParser(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.Parse("", "prefix")
+			if (err != nil) != (tt.wantErr != "") {
+				t.Fatalf("Parser.Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && err.Error() != tt.wantErr {
+				t.Errorf("Parser.Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	var wrong struct {
+		Cert string `config:"name:cert;required_if:tls_enabled"`
+	}
+	if _, err := NewParser(&wrong); err == nil {
+		t.Errorf("NewParser() should fail with wrong condition")
+	}
+
+	var unknown struct {
+		Cert   string `config:"name:cert;mode:cli;required_if:zzz=true"`
+		Prefix string `config:"name:prefix;mode:cli"`
+	}
+	p, _ := NewParser(&unknown)
+	os.Args = []string{"/app/test"}
+	if err := p.Parse("", "prefix"); err == nil {
+		t.Errorf("Parser.Parse() should fail with unknown config in condition")
+	}
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Validators available in validate tag without registration. Validators registered with same name have priority.
+// Some of them accept argument after "=". Ex.: `validate:url=https`
+var builtinValidators = map[string]func(value interface{}, arg string) error{
+	"file":     validateFile,
+	"dir":      validateDir,
+	"port":     validatePort,
+	"hostport": validateHostPort,
+	"url":      validateURL,
+}
+
+// Splitter of allowed values in validator argument. Ex.: `validate:url=http|https`
+const separatorChoices = "|"
+
+// Run check against each item of slice or each value of map (in order of keys), or against value itself.
+// So built-in validators can be used for lists. Ex.: `validate:port` of []int
+func checkItems(value reflect.Value, check func(item interface{}) error) error {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			err := check(value.Index(i).Interface())
+			if err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		return nil
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			err := check(value.MapIndex(key).Interface())
+			if err != nil {
+				return fmt.Errorf("value of %v: %w", key.Interface(), err)
+			}
+		}
+		return nil
+	}
+
+	return check(value.Interface())
+}
+
+// Check that path exists and is not a directory. Empty path is not checked
+func validateFile(value interface{}, _ string) error {
+	info, path, err := statPath(value)
+	if err != nil || info == nil {
+		return err
+	}
+	if info.IsDir() {
+		return errors.New(fmt.Sprintf("%s is a directory, not a file", path))
+	}
+
+	return nil
+}
+
+// Check that path exists and is a directory. Empty path is not checked
+func validateDir(value interface{}, _ string) error {
+	info, path, err := statPath(value)
+	if err != nil || info == nil {
+		return err
+	}
+	if !info.IsDir() {
+		return errors.New(fmt.Sprintf("%s is not a directory", path))
+	}
+
+	return nil
+}
+
+// Resolve path to absolute and get its info. Return nil info for empty path
+func statPath(value interface{}) (os.FileInfo, string, error) {
+	path := fmt.Sprint(value)
+	if path == "" {
+		return nil, "", nil
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, path, errors.New(fmt.Sprintf("%s does not exist", path))
+	}
+	if err != nil {
+		return nil, path, err
+	}
+
+	return info, path, nil
+}
+
+// Check that value is TCP/UDP port number: 1-65535
+func validatePort(value interface{}, _ string) error {
+	port, err := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+	if err != nil || port < 1 || port > 65535 {
+		return errors.New("should be port number from 1 to 65535")
+	}
+
+	return nil
+}
+
+// Check that value is host:port pair. Host can be empty (ex.: ":8080"), port should be number
+func validateHostPort(value interface{}, _ string) error {
+	_, port, err := net.SplitHostPort(fmt.Sprint(value))
+	if err != nil {
+		return errors.New("should be host:port pair")
+	}
+
+	return validatePort(port, "")
+}
+
+// Check that value is absolute url. Argument limits allowed schemes. Ex.: "https" or "http|https"
+func validateURL(value interface{}, schemes string) error {
+	u, err := url.Parse(fmt.Sprint(value))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return errors.New("should be absolute url")
+	}
+
+	if schemes == "" {
+		return nil
+	}
+	for _, scheme := range strings.Split(schemes, separatorChoices) {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("url scheme should be %s", strings.Join(strings.Split(schemes, separatorChoices), " or ")))
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_validateFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "cert.pem")
+	if err := os.WriteFile(file, []byte("cert"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		validate func(interface{}, string) error
+		wantErr  bool
+	}{
+		{name: "file", value: file, validate: validateFile},
+		{name: "file is dir", value: dir, validate: validateFile, wantErr: true},
+		{name: "file missing", value: filepath.Join(dir, "zzz"), validate: validateFile, wantErr: true},
+		{name: "empty", value: "", validate: validateFile},
+		{name: "dir", value: dir, validate: validateDir},
+		{name: "dir is file", value: file, validate: validateDir, wantErr: true},
+		{name: "dir missing", value: filepath.Join(dir, "zzz"), validate: validateDir, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.validate(tt.value, ""); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_networkValidators(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		arg      string
+		validate func(interface{}, string) error
+		wantErr  bool
+	}{
+		{name: "port", value: 8080, validate: validatePort},
+		{name: "port string", value: "443", validate: validatePort},
+		{name: "port zero", value: 0, validate: validatePort, wantErr: true},
+		{name: "port too big", value: uint(70000), validate: validatePort, wantErr: true},
+		{name: "hostport", value: "db:5432", validate: validateHostPort},
+		{name: "hostport ipv6", value: "[::1]:80", validate: validateHostPort},
+		{name: "hostport without host", value: ":8080", validate: validateHostPort},
+		{name: "hostport without port", value: "db", validate: validateHostPort, wantErr: true},
+		{name: "hostport wrong port", value: "db:http", validate: validateHostPort, wantErr: true},
+		{name: "url", value: "http://example.com/path", validate: validateURL},
+		{name: "url scheme", value: "https://example.com", arg: "https", validate: validateURL},
+		{name: "url schemes", value: "http://example.com", arg: "http|https", validate: validateURL},
+		{name: "url wrong scheme", value: "http://example.com", arg: "https", validate: validateURL, wantErr: true},
+		{name: "url relative", value: "/path", validate: validateURL, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.validate(tt.value, tt.arg); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_builtinValidators(t *testing.T) {
+	type testStruct struct {
+		Cert string `config:"name:cert;validate:file"`
+	}
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.parsedCli = map[string]string{"cert": "zzz.pem"}
+	err = p.fillStructWithValues(&cfg, "")
+	if err == nil {
+		t.Fatalf("Parser.fillStructWithValues() should fail with missing file")
+	}
+	abs, _ := filepath.Abs("zzz.pem")
+	if want := "Wrong value zzz.pem of cert (from cli): " + abs + " does not exist"; err.Error() != want {
+		t.Errorf("Parser.fillStructWithValues() error = %v, want %v", err, want)
+	}
+
+	var endpoint struct {
+		Endpoint string `config:"name:endpoint;validate:url=https"`
+	}
+	p, err = NewParser(&endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.parsedCli = map[string]string{"endpoint": "http://example.com"}
+	err = p.fillStructWithValues(&endpoint, "")
+	if want := "Wrong value http://example.com of endpoint (from cli): url scheme should be https"; err == nil || err.Error() != want {
+		t.Errorf("Parser.fillStructWithValues() error = %v, want %v", err, want)
+	}
+
+	var lists struct {
+		Ports []int          `config:"name:ports;validate:port"`
+		Peers map[string]int `config:"name:peers;validate:port"`
+	}
+	p, err = NewParser(&lists)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.parsedCli = map[string]string{"ports": "80,443", "peers": "a=5432,b=8080"}
+	if err = p.fillStructWithValues(&lists, ""); err != nil {
+		t.Errorf("Parser.fillStructWithValues() error = %v", err)
+	}
+	p.parsedCli = map[string]string{"ports": "80,0"}
+	err = p.fillStructWithValues(&lists, "")
+	if want := "Wrong value 80,0 of ports (from cli): item 1: should be port number from 1 to 65535"; err == nil || err.Error() != want {
+		t.Errorf("Parser.fillStructWithValues() error = %v, want %v", err, want)
+	}
+	p.parsedCli = map[string]string{"peers": "a=5432,b=0"}
+	err = p.fillStructWithValues(&lists, "")
+	if want := "Wrong value a=5432,b=0 of peers (from cli): value of b: should be port number from 1 to 65535"; err == nil || err.Error() != want {
+		t.Errorf("Parser.fillStructWithValues() error = %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Holder of parsed config struct. Readers get consistent snapshot without locking,
+// while it is replaced with new parsed struct on reload
+type Value[T any] struct {
+	v atomic.Value
+
+	mu sync.Mutex // Guards creation of parser by Parse
+	p  *Parser    // Parser made by Parse
+}
+
+// Create holder with initial config. It can be nil, and then Load returns nil until first Store or Parse
+func NewValue[T any](initial *T) *Value[T] {
+	v := &Value[T]{}
+	if initial != nil {
+		v.Store(initial)
+	}
+
+	return v
+}
+
+// Return current config snapshot. It should not be modified
+func (v *Value[T]) Load() *T {
+	cfg, _ := v.v.Load().(*T)
+	return cfg
+}
+
+// Replace current config snapshot
+func (v *Value[T]) Store(cfg *T) {
+	v.v.Store(cfg)
+}
+
+// Bind holder to parser of *T struct: copy of struct is stored after each successful filling of it
+// (by Parse, Reload or polling), so readers never see struct while it is refilled. It should be called before Parse
+func (v *Value[T]) Bind(p *Parser) error {
+	cfg, ok := p.in.(*T)
+	if !ok {
+		return errors.New(fmt.Sprintf("Parser fills %T, want %T", p.in, cfg))
+	}
+
+	store := func() {
+		snapshot := *cfg
+		v.Store(&snapshot)
+	}
+	if p.mu != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+	}
+	p.onRefill = append(p.onRefill, store)
+
+	return nil
+}
+
+// Parse config and replace current snapshot with it. Current snapshot is kept if parsing is failed.
+// First call makes parser with opts and binds holder to it (see Bind), next calls parse again with the same parser,
+// so opts of next calls are ignored. Call Close to stop polling of parser
+func (v *Value[T]) Parse(cfgPathConfig, envPrefixConfig string, opts ...Option) error {
+	p, err := v.parser(opts)
+	if err != nil {
+		return err
+	}
+
+	return p.Parse(cfgPathConfig, envPrefixConfig)
+}
+
+// Return parser made by Parse, so it can be reloaded. Return nil if Parse was not called
+func (v *Value[T]) Parser() *Parser {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.p
+}
+
+// Stop polling of parser made by Parse
+func (v *Value[T]) Close() {
+	if p := v.Parser(); p != nil {
+		p.Close()
+	}
+}
+
+// Return parser made by first Parse call, making it if needed
+func (v *Value[T]) parser(opts []Option) (*Parser, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.p != nil {
+		return v.p, nil
+	}
+
+	p, err := NewParser(new(T), opts...)
+	if err != nil {
+		return nil, err
+	}
+	err = v.Bind(&p)
+	if err != nil {
+		return nil, err
+	}
+	v.p = &p
+
+	return v.p, nil
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestValue(t *testing.T) {
+	type testStruct struct {
+		Port int `config:"name:port;mode:env"`
+	}
+
+	os.Args = []string{"/app/test"}
+
+	v := NewValue[testStruct](nil)
+	if v.Load() != nil {
+		t.Errorf("Value.Load() = %v, want nil", v.Load())
+	}
+
+	t.Setenv("PORT", "8080")
+	if err := v.Parse("", ""); err != nil {
+		t.Fatalf("Value.Parse() error = %v", err)
+	}
+	first := v.Load()
+	if first.Port != 8080 {
+		t.Errorf("Value.Load() = %v, want port 8080", first)
+	}
+
+	// Readers keep consistent snapshot while it is replaced
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if cfg := v.Load(); cfg.Port != 8080 && cfg.Port != 9090 {
+					t.Errorf("Value.Load() = %v", cfg)
+				}
+			}
+		}()
+	}
+	t.Setenv("PORT", "9090")
+	if err := v.Parse("", ""); err != nil {
+		t.Fatalf("Value.Parse() error = %v", err)
+	}
+	wg.Wait()
+
+	if v.Load().Port != 9090 || first.Port != 8080 {
+		t.Errorf("Value.Load() = %v, previous snapshot = %v", v.Load(), first)
+	}
+
+	// Failed parsing keeps current snapshot
+	t.Setenv("PORT", "ZZZ")
+	if err := v.Parse("", ""); err == nil {
+		t.Errorf("Value.Parse() should fail")
+	}
+	if v.Load().Port != 9090 {
+		t.Errorf("Value.Load() = %v, want port 9090", v.Load())
+	}
+
+	// Reload of parser made by Parse replaces snapshot
+	t.Setenv("PORT", "7070")
+	if _, err := v.Parser().Reload(); err != nil {
+		t.Fatalf("Parser.Reload() error = %v", err)
+	}
+	if v.Load().Port != 7070 {
+		t.Errorf("Value.Load() = %v, want port 7070", v.Load())
+	}
+	v.Close()
+
+	v.Store(&testStruct{Port: 1})
+	if v.Load().Port != 1 {
+		t.Errorf("Value.Load() = %v, want port 1", v.Load())
+	}
+}
+
+func TestValue_Bind(t *testing.T) {
+	type testStruct struct {
+		Port  int      `config:"name:port;mode:env"`
+		Hosts []string `config:"name:hosts;mode:env"`
+	}
+
+	os.Args = []string{"/app/test"}
+	t.Setenv("PORT", "8080")
+	t.Setenv("HOSTS", "a,b")
+
+	var cfg testStruct
+	p, err := NewParser(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewValue[testStruct](nil)
+	if err = NewValue[int](nil).Bind(&p); err == nil {
+		t.Errorf("Value.Bind() should fail for other type")
+	}
+	if err = v.Bind(&p); err != nil {
+		t.Fatalf("Value.Bind() error = %v", err)
+	}
+	if err = p.Parse("", ""); err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	first := v.Load()
+	if first == nil || first.Port != 8080 || first == &cfg {
+		t.Fatalf("Value.Load() = %v, want copy with port 8080", first)
+	}
+
+	// Readers keep consistent snapshot while parser is reloaded
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if cfg := v.Load(); cfg.Port != 8080 && cfg.Port != 9090 || len(cfg.Hosts) != 2 {
+					t.Errorf("Value.Load() = %v", cfg)
+				}
+			}
+		}()
+	}
+	t.Setenv("PORT", "9090")
+	for i := 0; i < 10; i++ {
+		if _, err = p.Reload(); err != nil {
+			t.Errorf("Parser.Reload() error = %v", err)
+		}
+	}
+	wg.Wait()
+
+	if v.Load().Port != 9090 || first.Port != 8080 {
+		t.Errorf("Value.Load() = %v, previous snapshot = %v", v.Load(), first)
+	}
+
+	t.Setenv("PORT", "ZZZ")
+	if _, err = p.Reload(); err == nil {
+		t.Errorf("Parser.Reload() should fail")
+	}
+	if v.Load().Port != 9090 {
+		t.Errorf("Value.Load() = %v, want port 9090 after failed reload", v.Load())
+	}
+}